@@ -0,0 +1,145 @@
+// Command sgbalance prints the deterministic timeline of the Stone Guard encounter, computed from balance.go values.
+//
+// It shows when abilities are cast and land, when room effects time out and when the earliest energy explosions happen,
+// and flags moments where several of them resolve within a short window:
+//
+//	go run ./cmd/sgbalance -minutes 5 -window 3
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"mogushan/stoneguard"
+)
+
+var (
+	fMinutes = flag.Int("minutes", 5, "length of the timeline, in minutes")
+	fScale   = flag.Int("scale", 5, "seconds per chart column")
+	fWindow  = flag.Float64("window", 3, "flag events that resolve within this many seconds of each other")
+	fTable   = flag.Bool("table", true, "print the event table")
+)
+
+func main() {
+	flag.Parse()
+	if *fMinutes <= 0 || *fScale <= 0 {
+		fmt.Fprintln(os.Stderr, "minutes and scale must be positive")
+		os.Exit(2)
+	}
+	out := os.Stdout
+	events := Timeline(*fMinutes * 60 * frameRate)
+	printValues(out)
+	if *fTable {
+		printTable(out, events)
+	}
+	printChart(out, events, *fMinutes*60, *fScale)
+	printOverlaps(out, Overlaps(events, int(*fWindow*frameRate)))
+}
+
+// fmtTime formats time in seconds as m:ss.
+func fmtTime(sec float64) string {
+	s := int(sec)
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// printValues prints balance values the timeline is based on.
+func printValues(w io.Writer) {
+	fmt.Fprintln(w, "Balance values:")
+	for _, a := range abilityTimers() {
+		fmt.Fprintf(w, "  %-6s after %3ds, cooldown %3ds, charge %ds\n", a.track, a.after, a.cooldown, a.charge)
+	}
+	fmt.Fprintf(w, "  %-6s delay %3ds, timeout %ds, power up each %ds\n", TrackRoom,
+		stoneguard.RoomEffectDelay, stoneguard.RoomEffectTimeout, stoneguard.RoomEffectPowerInterval)
+	fmt.Fprintf(w, "  %-6s delay %3ds, charge %ds\n", TrackEnergy,
+		stoneguard.EnergyDelay, stoneguard.EnergyExplosionChargeDur)
+	fmt.Fprintln(w)
+}
+
+// printTable prints all events as a table.
+func printTable(w io.Writer, events []Event) {
+	fmt.Fprintln(w, "Timeline:")
+	fmt.Fprintf(w, "  %6s  %-6s  %s\n", "time", "track", "event")
+	for _, e := range events {
+		fmt.Fprintf(w, "  %6s  %-6s  %s\n", fmtTime(e.Sec()), e.Track, e.Desc)
+	}
+	fmt.Fprintln(w)
+}
+
+// chartMark returns a chart symbol for the event kind. Events that players must react to have higher rank.
+func chartMark(k EventKind) (byte, int) {
+	switch k {
+	case EventCast:
+		return 'c', 1
+	case EventPower:
+		return '+', 1
+	case EventEffect:
+		return 'e', 2
+	case EventResolve:
+		return '*', 3
+	case EventTimeout:
+		return 'T', 3
+	case EventExplosion:
+		return 'X', 3
+	}
+	return '?', 0
+}
+
+// printChart prints events as an ASCII chart with one row per track.
+func printChart(w io.Writer, events []Event, dur, scale int) {
+	cols := (dur + scale - 1) / scale
+	var rows [trackMax][]byte
+	var rank [trackMax][]int
+	for t := range rows {
+		rows[t] = []byte(strings.Repeat(".", cols))
+		rank[t] = make([]int, cols)
+	}
+	for _, e := range events {
+		c := int(e.Sec()) / scale
+		if c >= cols {
+			continue
+		}
+		m, r := chartMark(e.Kind)
+		if r > rank[e.Track][c] {
+			rows[e.Track][c] = m
+			rank[e.Track][c] = r
+		}
+	}
+	fmt.Fprintf(w, "Chart (1 column = %ds; c = cast, * = lands, e = effect, + = power up, T = timeout, X = explosion):\n", scale)
+	// Put a time label each minute.
+	perMin := 60 / scale
+	if perMin < 1 {
+		perMin = 1
+	}
+	var ruler strings.Builder
+	for c := 0; c < cols; c += perMin {
+		lbl := fmtTime(float64(c * scale))
+		ruler.WriteString(lbl)
+		if pad := perMin - len(lbl); pad > 0 {
+			ruler.WriteString(strings.Repeat(" ", pad))
+		}
+	}
+	fmt.Fprintf(w, "  %-6s  %s\n", "", ruler.String())
+	for t := Track(0); t < trackMax; t++ {
+		fmt.Fprintf(w, "  %-6s  %s\n", t, rows[t])
+	}
+	fmt.Fprintln(w)
+}
+
+// printOverlaps prints groups of events that resolve close to each other.
+func printOverlaps(w io.Writer, overlaps []Overlap) {
+	if len(overlaps) == 0 {
+		fmt.Fprintf(w, "No overlaps within %gs.\n", *fWindow)
+		return
+	}
+	fmt.Fprintf(w, "Overlaps within %gs:\n", *fWindow)
+	for _, o := range overlaps {
+		var parts []string
+		for _, e := range o.Events {
+			parts = append(parts, fmt.Sprintf("%s %s at %s", e.Track, e.Desc, fmtTime(e.Sec())))
+		}
+		fmt.Fprintf(w, "  ! %s\n", strings.Join(parts, ", "))
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"sort"
+
+	"mogushan/stoneguard"
+)
+
+// frameRate is a server frame rate used to replay the encounter timers.
+const frameRate = 30
+
+// EventKind is a kind of the timeline event.
+type EventKind int
+
+const (
+	EventCast      = EventKind(iota) // ability starts charging
+	EventResolve                     // ability lands
+	EventEffect                      // first room effect starts
+	EventTimeout                     // room effect times out
+	EventPower                       // room effect power increases
+	EventExplosion                   // earliest energy explosion
+)
+
+// Track is a row of the timeline chart.
+type Track int
+
+const (
+	TrackRed = Track(iota)
+	TrackBlue
+	TrackGreen
+	TrackRoom
+	TrackEnergy
+	trackMax
+)
+
+func (t Track) String() string {
+	switch t {
+	case TrackRed:
+		return "Red"
+	case TrackBlue:
+		return "Blue"
+	case TrackGreen:
+		return "Green"
+	case TrackRoom:
+		return "Room"
+	case TrackEnergy:
+		return "Energy"
+	}
+	return "?"
+}
+
+// Event is a single event on the encounter timeline.
+type Event struct {
+	Frame int
+	Track Track
+	Kind  EventKind
+	Desc  string
+}
+
+// Sec returns event time in seconds.
+func (e Event) Sec() float64 {
+	return float64(e.Frame) / frameRate
+}
+
+// Resolves checks if the event is something players must react to.
+func (e Event) Resolves() bool {
+	switch e.Kind {
+	case EventResolve, EventTimeout, EventExplosion:
+		return true
+	}
+	return false
+}
+
+// abilityTimer mirrors the cast timer of the guard abilities.
+type abilityTimer struct {
+	track    Track
+	after    int // sec
+	cooldown int // sec
+	charge   int // sec
+}
+
+// abilityTimers returns cast timers for all abilities, as set in balance.go.
+func abilityTimers() []abilityTimer {
+	return []abilityTimer{
+		{track: TrackRed, after: stoneguard.RedAfter, cooldown: stoneguard.RedCooldown, charge: stoneguard.RedCharge},
+		{track: TrackBlue, after: stoneguard.BlueAfter, cooldown: stoneguard.BlueCooldown, charge: stoneguard.BlueCharge},
+		{track: TrackGreen, after: stoneguard.GreenAfter, cooldown: stoneguard.GreenCooldown, charge: stoneguard.GreenCharge},
+	}
+}
+
+// Timeline replays the encounter timers for a given number of frames and returns all events sorted by time.
+//
+// The timeline is deterministic, so it assumes that:
+//   - guards never leave the room and never stop fighting;
+//   - all guards stay close to each other, so energy explosions happen as early as possible;
+//   - explosions never switch the room effect, so the effect always runs until the timeout;
+//   - the Green projectile limit (GreenProjMax) is never reached.
+func Timeline(frames int) []Event {
+	var out []Event
+	add := func(f int, t Track, k EventKind, desc string) {
+		if f < frames {
+			out = append(out, Event{Frame: f, Track: t, Kind: k, Desc: desc})
+		}
+	}
+	// Abilities are cast after a delay, and then with a fixed cooldown.
+	for _, a := range abilityTimers() {
+		for f := a.after * frameRate; f < frames; f += a.cooldown * frameRate {
+			add(f, a.track, EventCast, "cast")
+			add(f+a.charge*frameRate, a.track, EventResolve, "lands")
+		}
+	}
+	// Room effect starts after a delay and switches when the timeout is exceeded.
+	// The game clears the first effect flag as soon as the first effect is set,
+	// so every effect, including the first one, lasts for RoomEffectTimeout.
+	start := stoneguard.RoomEffectDelay * frameRate
+	add(start, TrackRoom, EventEffect, "first effect")
+	timeout := stoneguard.RoomEffectTimeout * frameRate
+	for start < frames {
+		// No power step is reported on the timeout frame, the effect switches instead.
+		for p := 1; p*stoneguard.RoomEffectPowerInterval*frameRate < timeout; p++ {
+			add(start+p*stoneguard.RoomEffectPowerInterval*frameRate, TrackRoom, EventPower, "power up")
+		}
+		start += timeout + 1
+		add(start, TrackRoom, EventTimeout, "timeout")
+	}
+	// Energy is gathered each second after the initial delay, but not right after the explosion.
+	energy, exploded := 0, -1
+	for f := stoneguard.EnergyDelay * frameRate; f < frames; f += frameRate {
+		if exploded >= 0 && f-exploded <= stoneguard.EnergyDelay*frameRate {
+			continue
+		}
+		energy++
+		if energy > stoneguard.EnergyExplosionChargeDur {
+			add(f, TrackEnergy, EventExplosion, "explosion")
+			energy, exploded = 0, f
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Frame < out[j].Frame
+	})
+	return out
+}
+
+// Overlap is a group of events that resolve within a short window.
+type Overlap struct {
+	Events []Event
+}
+
+// Overlaps finds groups of events from different tracks that resolve within a given window (in frames).
+func Overlaps(events []Event, window int) []Overlap {
+	var res []Event
+	for _, e := range events {
+		if e.Resolves() {
+			res = append(res, e)
+		}
+	}
+	var out []Overlap
+	for i := 0; i < len(res); {
+		j := i + 1
+		for j < len(res) && res[j].Frame-res[j-1].Frame <= window {
+			j++
+		}
+		tracks := make(map[Track]bool)
+		for _, e := range res[i:j] {
+			tracks[e.Track] = true
+		}
+		if len(tracks) > 1 {
+			out = append(out, Overlap{Events: res[i:j]})
+		}
+		i = j
+	}
+	return out
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"testing"
+
+	"mogushan/stoneguard"
+)
+
+// eventFrames returns frames of all events of a given track and kind.
+func eventFrames(events []Event, t Track, k EventKind) []int {
+	var out []int
+	for _, e := range events {
+		if e.Track == t && e.Kind == k {
+			out = append(out, e.Frame)
+		}
+	}
+	return out
+}
+
+func equalFrames(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTimelineFrames(t *testing.T) {
+	const (
+		sec   = frameRate
+		total = 2 * 60 * sec
+	)
+	var (
+		effect  = stoneguard.RoomEffectDelay * sec
+		timeout = stoneguard.RoomEffectTimeout * sec
+		power   = stoneguard.RoomEffectPowerInterval * sec
+	)
+	// The first explosion happens once energy exceeds the charge duration, gathering starts after EnergyDelay.
+	explosion := (stoneguard.EnergyDelay + stoneguard.EnergyExplosionChargeDur) * sec
+	cases := []struct {
+		name  string
+		track Track
+		kind  EventKind
+		exp   []int
+	}{
+		{"red casts", TrackRed, EventCast, []int{
+			stoneguard.RedAfter * sec,
+			(stoneguard.RedAfter + stoneguard.RedCooldown) * sec,
+		}},
+		{"red lands", TrackRed, EventResolve, []int{
+			(stoneguard.RedAfter + stoneguard.RedCharge) * sec,
+			(stoneguard.RedAfter + stoneguard.RedCooldown + stoneguard.RedCharge) * sec,
+		}},
+		{"blue casts", TrackBlue, EventCast, []int{
+			stoneguard.BlueAfter * sec,
+			(stoneguard.BlueAfter + stoneguard.BlueCooldown) * sec,
+			(stoneguard.BlueAfter + 2*stoneguard.BlueCooldown) * sec,
+		}},
+		{"first effect", TrackRoom, EventEffect, []int{effect}},
+		{"timeouts", TrackRoom, EventTimeout, []int{
+			effect + timeout + 1,
+		}},
+		{"power ups", TrackRoom, EventPower, []int{
+			effect + power, effect + 2*power, effect + 3*power,
+			effect + timeout + 1 + power, effect + timeout + 1 + 2*power, effect + timeout + 1 + 3*power,
+		}},
+		{"explosions", TrackEnergy, EventExplosion, []int{
+			explosion,
+			explosion + (stoneguard.EnergyDelay+1+stoneguard.EnergyExplosionChargeDur)*sec,
+		}},
+	}
+	events := Timeline(total)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := eventFrames(events, c.track, c.kind)
+			if !equalFrames(got, c.exp) {
+				t.Fatalf("unexpected frames:\n%v\nexpected:\n%v", got, c.exp)
+			}
+		})
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Frame < events[i-1].Frame {
+			t.Fatalf("events are not sorted: %v before %v", events[i-1], events[i])
+		}
+	}
+}
+
+func TestTimelineNoPowerOnTimeout(t *testing.T) {
+	events := Timeline(10 * 60 * frameRate)
+	timeouts := make(map[int]bool)
+	for _, f := range eventFrames(events, TrackRoom, EventTimeout) {
+		timeouts[f] = true
+	}
+	for _, f := range eventFrames(events, TrackRoom, EventPower) {
+		// The effect switches one frame after the timeout, so check both frames.
+		if timeouts[f] || timeouts[f+1] {
+			t.Fatalf("power up reported on the timeout at frame %d", f)
+		}
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	events := []Event{
+		{Frame: 0, Track: TrackRed, Kind: EventCast},
+		{Frame: 10, Track: TrackRed, Kind: EventResolve},
+		{Frame: 20, Track: TrackBlue, Kind: EventResolve},
+		{Frame: 100, Track: TrackGreen, Kind: EventResolve},
+		{Frame: 105, Track: TrackGreen, Kind: EventResolve},
+		{Frame: 200, Track: TrackRoom, Kind: EventPower},
+		{Frame: 205, Track: TrackEnergy, Kind: EventExplosion},
+		{Frame: 300, Track: TrackRoom, Kind: EventTimeout},
+		{Frame: 310, Track: TrackEnergy, Kind: EventExplosion},
+		{Frame: 320, Track: TrackBlue, Kind: EventResolve},
+	}
+	cases := []struct {
+		name   string
+		window int
+		exp    [][]int // frames of events in each overlap
+	}{
+		{"none", 5, nil},
+		{"pairs", 10, [][]int{{10, 20}, {300, 310, 320}}},
+		{"chained", 100, [][]int{{10, 20, 100, 105, 205, 300, 310, 320}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := Overlaps(events, c.window)
+			if len(got) != len(c.exp) {
+				t.Fatalf("unexpected overlaps: %v", got)
+			}
+			for i, o := range got {
+				var frames []int
+				for _, e := range o.Events {
+					frames = append(frames, e.Frame)
+				}
+				if !equalFrames(frames, c.exp[i]) {
+					t.Fatalf("unexpected overlap %d: %v, expected %v", i, frames, c.exp[i])
+				}
+			}
+		})
+	}
+}
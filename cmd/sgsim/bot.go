@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+	"github.com/noxworld-dev/noxscript/ns/v4/damage"
+	"github.com/noxworld-dev/noxscript/ns/v4/enchant"
+	"github.com/noxworld-dev/opennox-lib/player"
+
+	"mogushan/stoneguard"
+)
+
+// Bot position strategies.
+const (
+	strategyStack  = "stack"  // all bots stand at the same point
+	strategySpread = "spread" // bots spread evenly around the guards
+	strategyPair   = "pair"   // bots stand in pairs
+)
+
+// entrancePos is a point near the room entrance. Bots position themselves between the guards and this point.
+var entrancePos = ns4.Ptf(4726, 4726)
+
+// botConfig sets the behavior of bot players.
+type botConfig struct {
+	Strategy string
+	DPS      float64 // damage per second dealt to guards
+	Range    float32 // attack range
+	Speed    float32 // movement speed per frame
+	HP       int
+	Kick     bool // kick the Green projectile
+	Dodge    bool // run from Blue circles, flames and clouds
+}
+
+// bot is a scripted player.
+type bot struct {
+	cfg botConfig
+	idx int
+	cnt int
+	pl  *simPlayer
+	dmg float64 // accumulated damage
+}
+
+// newBots creates bot players and their units.
+func newBots(w *world, n int, cfg botConfig) []*bot {
+	classes := []player.Class{player.Warrior, player.Wizard, player.Conjurer}
+	var bots []*bot
+	for i := 0; i < n; i++ {
+		pl := &simPlayer{name: fmt.Sprintf("bot%d", i+1)}
+		u := w.newObj("NewPlayer", entrancePos)
+		u.pl = pl
+		u.class = classes[i%len(classes)]
+		u.speed = cfg.Speed
+		u.maxHP, u.maxMana = cfg.HP, 150
+		pl.unit = u
+		w.players = append(w.players, pl)
+		bots = append(bots, &bot{cfg: cfg, idx: i, cnt: n, pl: pl})
+	}
+	return bots
+}
+
+// Revive restores the bot unit and puts it near the entrance.
+func (b *bot) Revive(w *world) {
+	u := b.pl.unit
+	u.hp, u.mana = u.maxHP, u.maxMana
+	u.enchants = nil
+	u.cause = ""
+	u.pos = entrancePos.Add(ns4.Ptf(float32(b.idx*10), -float32(b.idx*10)))
+}
+
+// guards returns alive guard units.
+func (w *world) guards() []*simObj {
+	var out []*simObj
+	for _, o := range w.objs {
+		if o.typ == stoneguard.BossModel && !o.deleted && o.hp > 0 {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// anchor returns a position the bot wants to stand at, according to the strategy.
+func (b *bot) anchor(center ns4.Pointf) ns4.Pointf {
+	dir := entrancePos.Sub(center).Normalize()
+	base := math.Atan2(float64(dir.Y), float64(dir.X))
+	dist := b.cfg.Range * 0.8
+	var ph float64
+	switch b.cfg.Strategy {
+	case strategySpread:
+		if b.cnt > 1 {
+			ph = -math.Pi/2 + math.Pi*float64(b.idx)/float64(b.cnt-1)
+		}
+	case strategyPair:
+		if groups := (b.cnt + 1) / 2; groups > 1 {
+			ph = -math.Pi/2 + math.Pi*float64(b.idx/2)/float64(groups-1)
+		}
+	}
+	ph += base
+	return center.Add(ns4.Ptf(dist*float32(math.Cos(ph)), dist*float32(math.Sin(ph))))
+}
+
+// isKicker checks if the bot is responsible for kicking the Green projectile.
+func (b *bot) isKicker(w *world) bool {
+	if !b.cfg.Kick {
+		return false
+	}
+	for _, p := range w.players {
+		if p.unit.hp > 0 {
+			return p == b.pl
+		}
+	}
+	return false
+}
+
+// danger returns a vector pointing away from all dangers at a given position, and whether there are any.
+func (b *bot) danger(w *world, pos ns4.Pointf, kicker bool) (ns4.Pointf, bool) {
+	var (
+		away  ns4.Pointf
+		found bool
+	)
+	check := func(c ns4.Pointf, r float32) {
+		vec := pos.Sub(c)
+		if float32(vec.Len()) >= r {
+			return
+		}
+		found = true
+		if vec.Len() == 0 {
+			vec = ns4.Ptf(1, 1)
+		}
+		away = away.Add(vec.Normalize())
+	}
+	for _, p := range w.dangers {
+		check(p, stoneguard.BlueOuterR+10)
+	}
+	for _, c := range w.clouds {
+		check(c.pos, toxicCloudR+10)
+	}
+	for _, o := range w.objs {
+		if o.deleted || !o.enabled || o.owner == nil {
+			continue
+		}
+		switch {
+		case o.typ == "BlueFlame":
+			check(o.pos, stoneguard.BlueOuterR+10)
+		case o.typ == "DeathBall":
+			check(o.pos, 60)
+		case o.typ == stoneguard.GreenProjModel && !kicker:
+			check(o.pos, stoneguard.GreenProjKickDist+20)
+		default:
+			if r, ok := flameR[o.typ]; ok {
+				check(o.pos, r+10)
+			}
+		}
+	}
+	return away, found
+}
+
+// Update runs the bot logic for one frame.
+func (b *bot) Update(w *world) {
+	u := b.pl.unit
+	if u.hp <= 0 {
+		return
+	}
+	if stoneguard.FightState() == stoneguard.BossWaiting && b.idx == 0 {
+		// Without a ready-check, the first bot pulls by walking up to the guards.
+		if guards := w.guards(); len(guards) != 0 {
+			moveTo(u, guards[0].pos, b.cfg.Speed)
+		}
+		return
+	}
+	if stoneguard.FightState() != stoneguard.BossFighting {
+		return
+	}
+	if u.HasEnchant(enchant.HELD) || u.HasEnchant(enchant.FREEZE) {
+		return
+	}
+	guards := w.guards()
+	if len(guards) == 0 {
+		return
+	}
+	var center ns4.Pointf
+	for _, g := range guards {
+		center = center.Add(g.pos)
+	}
+	center = center.Mul(1 / float32(len(guards)))
+
+	speed := b.cfg.Speed
+	if u.HasEnchant(enchant.SLOWED) {
+		speed /= 2
+	}
+	kicker := b.isKicker(w)
+	dest := b.anchor(center)
+	if kicker {
+		for _, o := range w.objs {
+			if !o.deleted && o.enabled && o.typ == stoneguard.GreenProjModel && o.pos.Sub(u.pos).Len() < 200 {
+				dest = o.pos
+				break
+			}
+		}
+	}
+	if away, ok := b.danger(w, u.pos, kicker); ok && b.cfg.Dodge {
+		dest = u.pos.Add(away.Normalize().Mul(speed * 4))
+	} else if b.cfg.Dodge {
+		// Do not step into a danger zone.
+		next := dest
+		if vec := dest.Sub(u.pos); float32(vec.Len()) > speed {
+			next = u.pos.Add(vec.Normalize().Mul(speed))
+		}
+		if _, ok := b.danger(w, next, kicker); ok {
+			dest = u.pos
+		}
+	}
+	moveTo(u, dest, speed)
+
+	if u.HasEnchant(enchant.CONFUSED) {
+		return
+	}
+	var (
+		targ *simObj
+		min  float64
+	)
+	for _, g := range guards {
+		if d := g.pos.Sub(u.pos).Len(); d < float64(b.cfg.Range) && (targ == nil || d < min) {
+			targ, min = g, d
+		}
+	}
+	if targ == nil {
+		return
+	}
+	b.dmg += b.cfg.DPS / frameRate
+	if dmg := int(b.dmg); dmg > 0 {
+		b.dmg -= float64(dmg)
+		targ.Damage(u, dmg, damage.BLADE)
+	}
+}
@@ -0,0 +1,202 @@
+// Command sgsim runs the Stone Guard encounter offline against a simulated world with bot players.
+//
+// It is used for balancing: instead of playing each change live, run thousands of pulls
+// and compare kill rate, kill time and death sources:
+//
+//	go run ./cmd/sgsim -pulls 1000 -bots 4 -strategy spread -dps 10
+//
+// The simulated world is an approximation. Guards walk straight to the target and hit in melee range,
+// bots deal constant damage to the closest guard in range, engine hazards (flames, DeathBall, Toxic Cloud)
+// use hardcoded values. Results are only meaningful when comparing runs with each other.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+
+	"mogushan/stoneguard"
+)
+
+var (
+	fPulls    = flag.Int("pulls", 100, "number of pulls to simulate")
+	fBots     = flag.Int("bots", 4, "number of bot players")
+	fStrategy = flag.String("strategy", strategySpread, "bot position strategy: stack, spread or pair")
+	fDPS      = flag.Float64("dps", 10, "damage per second of each bot")
+	fRange    = flag.Float64("range", 150, "attack range of bots")
+	fSpeed    = flag.Float64("speed", 4, "movement speed of bots, per frame")
+	fHP       = flag.Int("hp", 150, "health of bots")
+	fKick     = flag.Bool("kick", true, "bots kick the Green projectile")
+	fDodge    = flag.Bool("dodge", true, "bots run from Blue circles, flames and clouds")
+	fGuardDPS = flag.Float64("guard-dps", 5, "melee damage per second of guards")
+	fMaxTime  = flag.Int("maxtime", 600, "max duration of a single pull, in seconds")
+	fSeed     = flag.Int64("seed", 1, "random seed")
+	fVerbose  = flag.Bool("v", false, "print the encounter log")
+)
+
+// stats counts encounter events reported by the encounter.
+type stats struct {
+	kills          int
+	wipes          int
+	evades         int
+	effectTimeouts int
+	explosions     int
+	exploits       int
+	leaks          int
+}
+
+// EncounterEvent implements stoneguard.Observer.
+func (st *stats) EncounterEvent(e stoneguard.Event, n int) {
+	switch e {
+	case stoneguard.EventKill:
+		st.kills += n
+	case stoneguard.EventWipe:
+		st.wipes += n
+	case stoneguard.EventEvade:
+		st.evades += n
+	case stoneguard.EventEffectTimeout:
+		st.effectTimeouts += n
+	case stoneguard.EventExplosion:
+		st.explosions += n
+	case stoneguard.EventExploit:
+		st.exploits += n
+	case stoneguard.EventLeak:
+		st.leaks += n
+	}
+}
+
+// pullResult is a result of a single pull.
+type pullResult int
+
+const (
+	pullKill = pullResult(iota)
+	pullWipe
+	pullEvade
+	pullTimeout
+)
+
+func main() {
+	flag.Parse()
+	switch *fStrategy {
+	case strategyStack, strategySpread, strategyPair:
+	default:
+		fmt.Fprintf(os.Stderr, "unknown strategy: %q\n", *fStrategy)
+		os.Exit(2)
+	}
+	out := io.Writer(os.Stdout)
+	if !*fVerbose {
+		// The encounter logs to stdout, so silence it.
+		if null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0); err == nil {
+			os.Stdout = null
+		}
+	}
+
+	stoneguard.Debug = false
+	stoneguard.DebugOverlay = false
+
+	w := newWorld(*fSeed)
+	w.guardDPS = *fGuardDPS
+	ns4.SetRuntime(w)
+	stoneguard.Register()
+	st := &stats{}
+	stoneguard.SetObserver(st)
+
+	bots := newBots(w, *fBots, botConfig{
+		Strategy: *fStrategy,
+		DPS:      *fDPS,
+		Range:    float32(*fRange),
+		Speed:    float32(*fSpeed),
+		HP:       *fHP,
+		Kick:     *fKick,
+		Dodge:    *fDodge,
+	})
+
+	var (
+		results  = make(map[pullResult]int)
+		killTime int
+	)
+	for i := 0; i < *fPulls; i++ {
+		res, frames := runPull(w, bots, st)
+		results[res]++
+		if res == pullKill {
+			killTime += frames
+		}
+	}
+	report(out, results, killTime, st, w.deaths)
+}
+
+// runPull resets the encounter, starts it and runs it until it ends.
+// The fight is started with a ready-check if it's enabled, otherwise the bots pull it by walking up to the guards.
+// It returns the pull result and its duration in frames.
+func runPull(w *world, bots []*bot, st *stats) (pullResult, int) {
+	for _, b := range bots {
+		b.Revive(w)
+	}
+	w.mapInit()
+	w.step(bots)
+	if stoneguard.PullReadyCheck {
+		w.chat(bots[0].pl, stoneguard.PullCommand)
+	}
+	// Wait for the countdown or the pull.
+	for i := 0; i < (stoneguard.PullCountdown+1)*frameRate && stoneguard.FightState() != stoneguard.BossFighting; i++ {
+		w.step(bots)
+	}
+	before := *st
+	for frames := 0; frames < *fMaxTime*frameRate; frames++ {
+		w.step(bots)
+		switch {
+		case st.kills != before.kills:
+			return pullKill, frames
+		case st.wipes != before.wipes:
+			return pullWipe, frames
+		case st.evades != before.evades:
+			return pullEvade, frames
+		}
+	}
+	return pullTimeout, *fMaxTime * frameRate
+}
+
+// report prints the simulation summary.
+func report(out io.Writer, results map[pullResult]int, killTime int, st *stats, deaths map[string]int) {
+	pulls := *fPulls
+	perPull := func(v int) float64 {
+		if pulls == 0 {
+			return 0
+		}
+		return float64(v) / float64(pulls)
+	}
+	fmt.Fprintf(out, "pulls:            %d (%d bots, %s)\n", pulls, *fBots, *fStrategy)
+	fmt.Fprintf(out, "kill rate:        %.1f%%\n", 100*perPull(results[pullKill]))
+	if n := results[pullKill]; n > 0 {
+		fmt.Fprintf(out, "avg kill time:    %.1fs\n", float64(killTime)/float64(n)/frameRate)
+	}
+	fmt.Fprintf(out, "wipes:            %d\n", results[pullWipe])
+	fmt.Fprintf(out, "evades:           %d\n", results[pullEvade])
+	fmt.Fprintf(out, "pull timeouts:    %d\n", results[pullTimeout])
+	fmt.Fprintf(out, "effect timeouts:  %.2f per pull\n", perPull(st.effectTimeouts))
+	fmt.Fprintf(out, "explosions:       %.2f per pull\n", perPull(st.explosions))
+	fmt.Fprintf(out, "exploits:         %.2f per pull\n", perPull(st.exploits))
+	fmt.Fprintf(out, "leaked objects:   %d\n", st.leaks)
+
+	var causes []string
+	total := 0
+	for c, n := range deaths {
+		causes = append(causes, c)
+		total += n
+	}
+	sort.Slice(causes, func(i, j int) bool {
+		if deaths[causes[i]] != deaths[causes[j]] {
+			return deaths[causes[i]] > deaths[causes[j]]
+		}
+		return causes[i] < causes[j]
+	})
+	fmt.Fprintf(out, "deaths:           %d (%.2f per pull)\n", total, perPull(total))
+	for _, c := range causes {
+		fmt.Fprintf(out, "  %-14s  %5d  %s\n", c, deaths[c], strings.Repeat("#", 40*deaths[c]/total))
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+	"github.com/noxworld-dev/noxscript/ns/v4/damage"
+	"github.com/noxworld-dev/noxscript/ns/v4/enchant"
+	"github.com/noxworld-dev/opennox-lib/object"
+	"github.com/noxworld-dev/opennox-lib/player"
+
+	"mogushan/stoneguard"
+)
+
+// objType is a simulated object type. Only the name is known to the simulator.
+type objType struct {
+	ns4.ObjType
+	name string
+}
+
+func (t objType) Name() string { return t.name }
+
+// simObj is a simulated map object.
+//
+// It embeds ns4.Obj only to satisfy the interface: methods that are not implemented here
+// will panic, which means the encounter started using them and the simulator must be extended.
+type simObj struct {
+	ns4.Obj
+	w       *world
+	typ     string
+	pos     ns4.Pointf
+	enabled bool
+	deleted bool
+	frozen  bool
+	owner   ns4.Obj
+
+	hp, maxHP     int
+	mana, maxMana int
+	speed         float32
+	mass          float32
+	aggression    float32
+	enchants      map[enchant.Enchant]int // frame when enchant ends, or -1 for infinite
+
+	walkTo   *ns4.Pointf // guard walk target
+	attack   ns4.Obj     // guard attack target
+	attacked map[ns4.Obj]int
+
+	pl    *simPlayer // set for player units
+	class player.Class
+	cause string // source of the last damage
+}
+
+func (o *simObj) Type() ns4.ObjType   { return objType{name: o.typ} }
+func (o *simObj) Pos() ns4.Pointf     { return o.pos }
+func (o *simObj) SetPos(p ns4.Pointf) { o.pos = p }
+func (o *simObj) IsEnabled() bool     { return o.enabled }
+func (o *simObj) Enable(v bool)       { o.enabled = v }
+
+func (o *simObj) Delete() {
+	o.deleted = true
+	o.enabled = false
+}
+
+func (o *simObj) Class() object.Class {
+	switch {
+	case o.pl != nil:
+		return object.ClassPlayer
+	case o.typ == stoneguard.BossModel:
+		return object.ClassMonster
+	}
+	return 0
+}
+
+func (o *simObj) Flags() object.Flags {
+	var f object.Flags
+	if o.deleted {
+		f |= object.FlagDestroyed
+	}
+	if o.maxHP > 0 && o.hp <= 0 {
+		f |= object.FlagDead
+	}
+	return f
+}
+
+func (o *simObj) GetClass() player.Class { return o.class }
+
+func (o *simObj) Player() ns4.Player {
+	if o.pl == nil {
+		return nil
+	}
+	return o.pl
+}
+
+func (o *simObj) CurrentHealth() int { return o.hp }
+func (o *simObj) MaxHealth() int     { return o.maxHP }
+func (o *simObj) SetHealth(v int)    { o.hp = v }
+
+func (o *simObj) SetMaxHealth(v int) {
+	o.maxHP = v
+	o.hp = v
+}
+
+func (o *simObj) CurrentMana() int { return o.mana }
+func (o *simObj) MaxMana() int     { return o.maxMana }
+func (o *simObj) SetMana(v int)    { o.mana = v }
+
+func (o *simObj) BaseSpeed() float32     { return o.speed }
+func (o *simObj) SetBaseSpeed(v float32) { o.speed = v }
+func (o *simObj) Mass() float32          { return o.mass }
+func (o *simObj) SetMass(v float32)      { o.mass = v }
+
+func (o *simObj) AggressionLevel(v float32) { o.aggression = v }
+func (o *simObj) LookWithAngle(int)         {}
+func (o *simObj) Freeze(v bool)             { o.frozen = v }
+
+func (o *simObj) ChatStrTimer(string, ns4.Duration) {}
+func (o *simObj) DestroyChat()                      {}
+
+func (o *simObj) HasOwner(owner ns4.Obj) bool { return owner != nil && o.owner == owner }
+func (o *simObj) SetOwner(owner ns4.Obj)      { o.owner = owner }
+
+func (o *simObj) Enchant(e enchant.Enchant, dt ns4.Duration) {
+	if o.enchants == nil {
+		o.enchants = make(map[enchant.Enchant]int)
+	}
+	o.enchants[e] = o.w.until(dt)
+}
+
+func (o *simObj) EnchantOff(e enchant.Enchant) {
+	delete(o.enchants, e)
+}
+
+func (o *simObj) HasEnchant(e enchant.Enchant) bool {
+	end, ok := o.enchants[e]
+	if !ok {
+		return false
+	}
+	if end >= 0 && end <= o.w.frame {
+		delete(o.enchants, e)
+		return false
+	}
+	return true
+}
+
+func (o *simObj) WalkTo(p ns4.Pointf) {
+	o.walkTo = &p
+}
+
+func (o *simObj) Attack(targ ns4.Positioner) {
+	o.walkTo = nil
+	o.attack, _ = targ.(ns4.Obj)
+}
+
+func (o *simObj) IsAttackedBy(by ns4.Obj) bool {
+	last, ok := o.attacked[by]
+	return ok && o.w.frame-last < o.w.FrameRate()
+}
+
+// Damage is called by the encounter script. Script damage has no source, so it's classified by type.
+func (o *simObj) Damage(src ns4.Obj, amount int, typ damage.Type) {
+	if src != nil {
+		if o.attacked == nil {
+			o.attacked = make(map[ns4.Obj]int)
+		}
+		o.attacked[src] = o.w.frame
+	}
+	cause := "script"
+	if src == nil {
+		switch typ {
+		case damage.ZAP_RAY:
+			cause = "explosion"
+		case damage.ELECTRIC:
+			cause = "blue"
+		case damage.FLAME:
+			cause = "room:red"
+		case damage.POISON:
+			cause = "room:green"
+		case damage.DEATH_MAGIC:
+			cause = "petrify"
+		}
+	} else if s, ok := src.(*simObj); ok && s.pl != nil {
+		cause = "player"
+	}
+	o.hurt(amount, cause)
+}
+
+// hurt deals damage to the object and records the death cause for players.
+func (o *simObj) hurt(amount int, cause string) {
+	if o.deleted || o.maxHP <= 0 || o.hp <= 0 {
+		return
+	}
+	if o.HasEnchant(enchant.INVULNERABLE) {
+		return
+	}
+	o.hp -= amount
+	if o.hp > 0 {
+		return
+	}
+	o.hp = 0
+	if o.pl != nil {
+		o.cause = cause
+		o.w.deaths[cause]++
+	}
+}
+
+// simPlayer is a simulated player controlled by a bot.
+type simPlayer struct {
+	ns4.Player
+	name string
+	unit *simObj
+}
+
+func (p *simPlayer) Name() string            { return p.name }
+func (p *simPlayer) Unit() ns4.Obj           { return p.unit }
+func (p *simPlayer) Print(ns4.StringID)      {}
+func (p *simPlayer) PrintStr(string)         {}
+func (p *simPlayer) HasTeam(t ns4.Team) bool { return false }
+func (p *simPlayer) Team() ns4.Team          { return nil }
+
+// simWall is a simulated wall. The simulator does not model collisions, so walls only keep their state.
+type simWall struct {
+	ns4.WallObj
+	enabled bool
+}
+
+func (w *simWall) IsEnabled() bool { return w.enabled }
+func (w *simWall) Enable(v bool)   { w.enabled = v }
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+	"github.com/noxworld-dev/noxscript/ns/v4/effect"
+	"github.com/noxworld-dev/noxscript/ns/v4/enchant"
+	"github.com/noxworld-dev/noxscript/ns/v4/spell"
+
+	"mogushan/stoneguard"
+)
+
+// frameRate is a server frame rate used by the simulator.
+const frameRate = 30
+
+// Hazard values of the simulated world. They approximate engine behavior the script relies on.
+const (
+	// flameDamage is a damage dealt by a flame object each flameInterval frames.
+	flameDamage   = 2
+	flameInterval = 6
+	// deathBallR is a distance at which the DeathBall hits a player.
+	deathBallR = 25
+	// deathBallDamage is a damage of the DeathBall hit.
+	deathBallDamage = 60
+	// toxicCloudR is a radius of the Toxic Cloud.
+	toxicCloudR = 60
+	// toxicCloudDur is a duration of the Toxic Cloud in frames.
+	toxicCloudDur = 5 * frameRate
+	// toxicCloudDamage is a damage dealt by the cloud each toxicCloudInterval frames.
+	toxicCloudDamage   = 3
+	toxicCloudInterval = 10
+	// meleeDist is a distance at which guards can hit players.
+	meleeDist = 35
+)
+
+// flameR is a damage radius for each flame model.
+var flameR = map[string]float32{
+	"SmallFlame":  10,
+	"MediumFlame": 14,
+	"Flame":       18,
+	"LargeFlame":  22,
+	"BlueFlame":   15,
+}
+
+// cloud is an active Toxic Cloud.
+type cloud struct {
+	pos ns4.Pointf
+	end int
+}
+
+// world is a simulated script runtime. It implements only the parts of ns4.Implementation used by the encounter.
+type world struct {
+	ns4.Implementation
+	rnd     *rand.Rand
+	frame   int
+	objs    []*simObj
+	players []*simPlayer
+	walls   map[[2]int]*simWall
+	clouds  []cloud
+	dangers []ns4.Pointf // Blue spell targets seen this frame
+
+	onFrame []ns4.FrameFunc
+	onInit  []ns4.MapEventFunc
+	onChat  []ns4.ChatFunc
+
+	guardDPS float64
+	guardDmg float64
+	deaths   map[string]int
+}
+
+func newWorld(seed int64) *world {
+	return &world{
+		rnd:    rand.New(rand.NewSource(seed)),
+		walls:  make(map[[2]int]*simWall),
+		deaths: make(map[string]int),
+	}
+}
+
+func (w *world) Frame() int          { return w.frame }
+func (w *world) Time() time.Duration { return time.Duration(w.frame) * time.Second / frameRate }
+func (w *world) FrameRate() int      { return frameRate }
+
+// until converts the duration to the end frame, or -1 for infinite duration.
+func (w *world) until(dt ns4.Duration) int {
+	if dt.IsInfinite() {
+		return -1
+	}
+	if n, ok := dt.Frames(); ok {
+		return w.frame + n
+	}
+	t, _ := dt.Time()
+	return w.frame + int(t*frameRate/time.Second)
+}
+
+func (w *world) Random(min, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + w.rnd.Intn(max-min+1)
+}
+
+func (w *world) RandomFloat(min, max float32) float32 {
+	return min + w.rnd.Float32()*(max-min)
+}
+
+func (w *world) newObj(typ string, pos ns4.Pointf) *simObj {
+	o := &simObj{w: w, typ: typ, pos: pos, enabled: true, speed: 1, aggression: 1}
+	w.objs = append(w.objs, o)
+	return o
+}
+
+func (w *world) CreateObject(typ string, pos ns4.Positioner) ns4.Obj {
+	return w.newObj(typ, pos.Pos())
+}
+
+func (w *world) FindObjects(fnc func(it ns4.Obj) bool, conds ...ns4.ObjCond) int {
+	n := 0
+next:
+	for _, o := range w.objs {
+		if o.deleted {
+			continue
+		}
+		for _, c := range conds {
+			if !c.Matches(o) {
+				continue next
+			}
+		}
+		n++
+		if fnc != nil && !fnc(o) {
+			break
+		}
+	}
+	return n
+}
+
+func (w *world) Players() []ns4.Player {
+	out := make([]ns4.Player, 0, len(w.players))
+	for _, p := range w.players {
+		out = append(out, p)
+	}
+	return out
+}
+
+func (w *world) HostPlayer() ns4.Player {
+	if len(w.players) == 0 {
+		return nil
+	}
+	return w.players[0]
+}
+
+func (w *world) GetHost() ns4.Obj {
+	if len(w.players) == 0 {
+		return nil
+	}
+	return w.players[0].unit
+}
+
+func (w *world) PrintStr(string)      {}
+func (w *world) PrintStrToAll(string) {}
+
+func (w *world) Wall(x, y int) ns4.WallObj {
+	k := [2]int{x, y}
+	wl := w.walls[k]
+	if wl == nil {
+		wl = &simWall{}
+		w.walls[k] = wl
+	}
+	return wl
+}
+
+func (w *world) Effect(e effect.Effect, p1, p2 ns4.Positioner) {
+	// Blue spell shows lightning from the guard to the target position while charging.
+	if o, ok := p1.(*simObj); ok && e == effect.LIGHTNING && o.typ == stoneguard.BossModel {
+		w.dangers = append(w.dangers, p2.Pos())
+	}
+}
+
+func (w *world) CastSpell(sp spell.Spell, src, targ ns4.Positioner) {
+	switch sp {
+	case spell.COUNTERSPELL:
+		if o, ok := targ.(*simObj); ok {
+			o.EnchantOff(enchant.CHARMING)
+		}
+	case spell.TOXIC_CLOUD:
+		w.clouds = append(w.clouds, cloud{pos: targ.Pos(), end: w.frame + toxicCloudDur})
+	}
+}
+
+func (w *world) OnFrame(fnc ns4.FrameFunc) { w.onFrame = append(w.onFrame, fnc) }
+func (w *world) OnMapEvent(typ ns4.MapEvent, fnc ns4.MapEventFunc) {
+	if typ == ns4.MapInitialize {
+		w.onInit = append(w.onInit, fnc)
+	}
+}
+func (w *world) OnChat(fnc ns4.ChatFunc) { w.onChat = append(w.onChat, fnc) }
+
+// mapInit simulates map loading: removes all non-player objects and calls MapInitialize handlers.
+func (w *world) mapInit() {
+	objs := w.objs[:0]
+	for _, o := range w.objs {
+		if o.pl != nil {
+			objs = append(objs, o)
+		} else {
+			o.Delete()
+		}
+	}
+	w.objs = objs
+	w.clouds = nil
+	for _, fnc := range w.onInit {
+		fnc()
+	}
+}
+
+// chat simulates a chat message from the player.
+func (w *world) chat(p *simPlayer, msg string) {
+	for _, fnc := range w.onChat {
+		msg = fnc(nil, p, p.unit, msg)
+		if msg == "" {
+			return
+		}
+	}
+}
+
+// step runs a single server frame: script handlers first, then the world simulation.
+func (w *world) step(bots []*bot) {
+	w.dangers = w.dangers[:0]
+	for _, fnc := range w.onFrame {
+		fnc()
+	}
+	for _, b := range bots {
+		b.Update(w)
+	}
+	for _, o := range w.objs {
+		if o.typ == stoneguard.BossModel && !o.deleted {
+			w.guardUpdate(o)
+		}
+	}
+	w.hazardsUpdate()
+	w.frame++
+	if w.frame%(60*frameRate) == 0 {
+		w.compact()
+	}
+}
+
+// compact removes deleted objects from the object list.
+func (w *world) compact() {
+	objs := w.objs[:0]
+	for _, o := range w.objs {
+		if !o.deleted {
+			objs = append(objs, o)
+		}
+	}
+	w.objs = objs
+}
+
+// moveTo moves the object toward a given point with a given speed.
+func moveTo(o *simObj, p ns4.Pointf, speed float32) bool {
+	vec := p.Sub(o.pos)
+	if d := float32(vec.Len()); d <= speed {
+		o.pos = p
+		return true
+	}
+	o.pos = o.pos.Add(vec.Normalize().Mul(speed))
+	return false
+}
+
+// guardUpdate moves the guard and makes it hit players in melee range.
+func (w *world) guardUpdate(g *simObj) {
+	if g.frozen || g.hp <= 0 || g.HasEnchant(enchant.FREEZE) {
+		return
+	}
+	speed := g.speed
+	if g.HasEnchant(enchant.SLOWED) {
+		speed /= 2
+	}
+	if g.walkTo != nil {
+		if moveTo(g, *g.walkTo, speed) {
+			g.walkTo = nil
+		}
+		return
+	}
+	if g.aggression <= 0 {
+		return
+	}
+	targ, _ := g.attack.(*simObj)
+	if targ == nil || targ.hp <= 0 || targ.deleted {
+		targ = w.closestPlayer(g.pos)
+	}
+	if targ == nil {
+		return
+	}
+	if float32(targ.pos.Sub(g.pos).Len()) > meleeDist {
+		moveTo(g, targ.pos, speed)
+		return
+	}
+	w.guardDmg += w.guardDPS / frameRate
+	if dmg := int(w.guardDmg); dmg > 0 {
+		w.guardDmg -= float64(dmg)
+		targ.hurt(dmg, "melee")
+	}
+}
+
+// closestPlayer returns the closest alive player unit.
+func (w *world) closestPlayer(pos ns4.Pointf) *simObj {
+	var (
+		best *simObj
+		min  float64
+	)
+	for _, p := range w.players {
+		u := p.unit
+		if u.hp <= 0 {
+			continue
+		}
+		if d := u.pos.Sub(pos).Len(); best == nil || d < min {
+			best, min = u, d
+		}
+	}
+	return best
+}
+
+// hazardsUpdate applies damage of the objects that deal damage in the engine itself: flames, DeathBall and clouds.
+func (w *world) hazardsUpdate() {
+	for i := 0; i < len(w.clouds); i++ {
+		if w.clouds[i].end <= w.frame {
+			w.clouds = append(w.clouds[:i], w.clouds[i+1:]...)
+			i--
+		}
+	}
+	for _, o := range w.objs {
+		if o.deleted || !o.enabled {
+			continue
+		}
+		if r, ok := flameR[o.typ]; ok && w.frame%flameInterval == 0 {
+			cause := "red-flames"
+			if o.typ == "BlueFlame" {
+				cause = "blue"
+			}
+			for _, p := range w.players {
+				if float32(p.unit.pos.Sub(o.pos).Len()) < r {
+					p.unit.hurt(flameDamage, cause)
+				}
+			}
+		}
+		if o.typ == "DeathBall" {
+			for _, p := range w.players {
+				if p.unit.hp > 0 && p.unit.pos.Sub(o.pos).Len() < deathBallR {
+					p.unit.hurt(deathBallDamage, "deathball")
+					o.Delete()
+					break
+				}
+			}
+		}
+	}
+	if w.frame%toxicCloudInterval == 0 {
+		for _, c := range w.clouds {
+			for _, p := range w.players {
+				if p.unit.pos.Sub(c.pos).Len() < toxicCloudR {
+					p.unit.hurt(toxicCloudDamage, "toxic-cloud")
+				}
+			}
+		}
+	}
+}
@@ -53,27 +53,73 @@ func (g *BlueAbility) Update(b *Guard) {
 	}
 	// ability charged - create new spell and reset charge
 	g.charge = 0
+	g.Cast(b, nil)
+}
 
-	// pick random player in boss room
-	var players []ns4.Obj
-	b.s.EachPlayerInRoom(func(u ns4.Obj) {
-		players = append(players, u)
-	})
-	if len(players) == 0 {
-		return // no players in room
+// Cast a new Blue spell at a given target position. If target is nil, a random player in the room is selected.
+func (g *BlueAbility) Cast(b *Guard, targ ns4.Obj) {
+	// make sure the spell will be updated, even if cast before BlueAfter
+	if g.frame < BlueAfter*ns4.FrameRate() {
+		g.frame = BlueAfter * ns4.FrameRate()
 	}
-	ind := ns4.Random(0, len(players)-1)
-	targ := players[ind].Pos()
+	if targ == nil {
+		// pick random target in boss room
+		targ = b.s.randomTarget(b.color, nil)
+		if targ == nil {
+			return // no players in room
+		}
+	}
+	pos := targ.Pos()
+	b.s.Announce(PriorityHigh, msgTargets, b.color.GuardName(), playerName(targ))
+	b.s.AnnounceTo(targ, PriorityHigh, msgTargetsYou, b.color.GuardName())
+	b.startCast(tr(msgAbilityBlue), BlueCharge)
+	// warn players where the spell will land
+	tg := b.s.TelegraphCircle(pos, BlueOuterR, BlueCharge)
 	// add new spell to active ones
 	g.active = append(g.active, &blueSpell{
-		target: targ,
+		s:      b.s,
+		unit:   targ,
+		target: pos,
+		tg:     tg,
 	})
 	g.notFirst = true
 }
 
+// TargetLost handles charging Blue spells aimed at a unit that left the fight, according to BlueTargetLost.
+func (g *BlueAbility) TargetLost(b *Guard, u ns4.Obj) {
+	for _, a := range g.active {
+		if a.stop || a.unit != u || a.frame >= BlueCharge*ns4.FrameRate() {
+			continue
+		}
+		policy := BlueTargetLost
+		if policy == TargetRetarget {
+			if targ := b.s.randomTarget(b.color, func(v ns4.Obj) bool { return v == u }); targ != nil {
+				a.unit = targ
+				a.target = targ.Pos()
+				a.tg.SetPos(a.target)
+				b.retarget(targ)
+				continue
+			}
+			policy = TargetFizzle // no one else to target
+		}
+		switch policy {
+		case TargetFizzle:
+			b.stopCast()
+			a.tg.Delete()
+			a.stop = true
+		case TargetFinish:
+			// the spell lands where it was aimed
+			a.unit = nil
+		}
+	}
+}
+
 // blueSpell stores state of a single Blue spell.
 type blueSpell struct {
-	target ns4.Pointf
+	s      *State
+	unit   ns4.Obj    // unit targeted by the spell, while it charges
+	target ns4.Pointf // position where the spell lands
+	tg     *Telegraph // telegraph circle shown while charging
 	frame  int
 	outer  ns4.Objects // outer orbs
 	inner  ns4.Objects // inner orbs
@@ -83,12 +129,12 @@ type blueSpell struct {
 
 // Delete a single Blue spell.
 func (g *blueSpell) Delete() {
-	g.outer.Delete()
+	g.s.objs.Delete(g.outer...)
 	g.outer = nil
-	g.inner.Delete()
+	g.s.objs.Delete(g.inner...)
 	g.inner = nil
 	if g.flame != nil {
-		g.flame.Delete()
+		g.s.objs.Delete(g.flame)
 		g.flame = nil
 	}
 }
@@ -105,17 +151,17 @@ func (g *blueSpell) Update(b *Guard) {
 		return
 	}
 	if g.flame == nil {
-		g.flame = ns4.CreateObject(BlueDangerModel, boss)
+		g.flame = b.s.objs.Create(b.owner(), BlueDangerModel, boss)
 		g.flame.SetOwner(boss)
 		g.flame.SetPos(targ)
 		for i := 0; i < BlueOuterCnt; i++ {
-			o := ns4.CreateObject(BlueOuterModel, boss)
+			o := b.s.objs.Create(b.owner(), BlueOuterModel, boss)
 			o.SetOwner(boss)
 			o.SetPos(targ)
 			g.outer = append(g.outer, o)
 		}
 		for i := 0; i < BlueInnerCnt; i++ {
-			o := ns4.CreateObject(BlueInnerModel, boss)
+			o := b.s.objs.Create(b.owner(), BlueInnerModel, boss)
 			o.SetOwner(boss)
 			o.SetPos(targ)
 			g.inner = append(g.inner, o)
@@ -124,16 +170,18 @@ func (g *blueSpell) Update(b *Guard) {
 
 	hit := false
 	blueHit := false
-	b.s.EachPlayerInRoom(func(u ns4.Obj) {
+	b.s.EachTargetInRoom(func(u ns4.Obj) {
 		d := u.Pos().Sub(targ).Len()
 		if !hit && d < BlueInnerR {
 			hit = true
 			if b.color == b.s.curEffect {
 				u.Damage(nil, BlueInnerDamageWeak, damage.ELECTRIC)
 				u.Enchant(enchant.HELD, ns4.Seconds(BlueInnerStunWeak))
+				b.s.AttachMarker(u, DebuffStunned, BlueInnerStunWeak)
 			} else {
 				u.Damage(nil, BlueInnerDamage, damage.ELECTRIC)
 				u.Enchant(enchant.HELD, ns4.Seconds(BlueInnerStun))
+				b.s.AttachMarker(u, DebuffStunned, BlueInnerStun)
 			}
 		} else if d < BlueOuterR {
 			if b.color != b.s.curEffect {
@@ -53,6 +53,13 @@ func (g *RedAbility) Update(b *Guard) {
 		}
 	}
 
+	// player creatures are not in the roster, so check them here
+	for _, a := range g.active {
+		if !a.stop && !a.lost && a.target.Player() == nil && !isAlive(a.target) {
+			g.TargetLost(b, a.target)
+		}
+	}
+
 	// update active abilities
 	for _, a := range g.active {
 		a.Update(b)
@@ -65,55 +72,111 @@ func (g *RedAbility) Update(b *Guard) {
 	}
 	// ability charged - create new spell and reset charge
 	g.charge = 0
+	g.Cast(b, nil)
+}
 
+// Cast a new Red spell on a given target. If target is nil, a random player in the room is selected.
+func (g *RedAbility) Cast(b *Guard, targ ns4.Obj) {
+	// make sure the spell will be updated, even if cast before RedAfter
+	if g.frame < RedAfter*ns4.FrameRate() {
+		g.frame = RedAfter * ns4.FrameRate()
+	}
 	if RedOnlyOne {
 		g.reset()
 	}
-
-	// pick random player position in boss room
-	var players []ns4.Obj
-	b.s.EachPlayerInRoom(func(u ns4.Obj) {
-		if !g.hasTarget(u) {
-			players = append(players, u)
+	if targ == nil {
+		// pick random target in boss room, which is not targeted already
+		targ = b.s.randomTarget(b.color, g.hasTarget)
+		if targ == nil {
+			return // no players in room
 		}
-	})
-	if len(players) == 0 {
-		return // no players in room
 	}
-	ind := ns4.Random(0, len(players)-1)
-	targ := players[ind]
+	b.s.Announce(PriorityHigh, msgTargets, b.color.GuardName(), playerName(targ))
+	b.s.AnnounceTo(targ, PriorityHigh, msgTargetsYou, b.color.GuardName())
+	b.startCast(tr(msgAbilityRed), RedCharge)
+	b.s.AttachMarker(targ, DebuffRedTarget, 0)
 	// add new spell to active ones
 	g.active = append(g.active, &redSpell{
+		s:      b.s,
 		target: targ,
 	})
 	g.notFirst = true
 }
 
+// TargetLost handles Red spells targeting a unit that left the fight, according to RedTargetLost.
+func (g *RedAbility) TargetLost(b *Guard, u ns4.Obj) {
+	for _, a := range g.active {
+		if a.stop || a.lost || a.target != u {
+			continue
+		}
+		policy := RedTargetLost
+		if policy == TargetRetarget {
+			targ := b.s.randomTarget(b.color, func(v ns4.Obj) bool {
+				return v == u || g.hasTarget(v)
+			})
+			if targ != nil {
+				b.s.DetachMarker(u, DebuffRedTarget)
+				a.target = targ
+				b.s.AttachMarker(targ, DebuffRedTarget, 0)
+				b.retarget(targ)
+				continue
+			}
+			policy = TargetFizzle // no one else to target
+		}
+		switch policy {
+		case TargetFizzle:
+			if a.frame < RedCharge*ns4.FrameRate() {
+				b.stopCast()
+			}
+			a.stop = true
+		case TargetFinish:
+			// keep burning at the last known position until it wears off
+			b.s.DetachMarker(u, DebuffRedTarget)
+			a.lost = true
+		}
+	}
+}
+
 // redSpell stores state of a single Red spell.
 type redSpell struct {
+	s      *State
 	target ns4.Obj
+	pos    ns4.Pointf // last known target position
+	lost   bool       // target left the fight, spell stays at the last known position
 	frame  int
 	line   ns4.Objects // flame line between the boss and target
 	reduce int
 	strong [4]ns4.Obj // flame variations for the strong flame that tries to hit the player (large to small)
 	weak   [4]ns4.Obj // weak flames that will circle the target for the weak spell variant
+	tg     *Telegraph // telegraph line shown while charging
 	stop   bool
 }
 
 // Delete a single Red spell.
 func (g *redSpell) Delete() {
+	// remove the target marker
+	g.s.DetachMarker(g.target, DebuffRedTarget)
 	// delete the flame line
-	g.line.Delete()
+	g.s.objs.Delete(g.line...)
 	g.line = nil
 	// delete strong target effect
 	for i, a := range g.strong {
-		a.Delete()
-		g.strong[i] = nil
+		if a != nil {
+			g.s.objs.Delete(a)
+			g.strong[i] = nil
+		}
 	}
 	// delete weak target effect
 	for i, a := range g.weak {
-		a.Delete()
-		g.weak[i] = nil
+		if a != nil {
+			g.s.objs.Delete(a)
+			g.weak[i] = nil
+		}
+	}
+	// delete the telegraph
+	if g.tg != nil {
+		g.tg.Delete()
+		g.tg = nil
 	}
 }
 
@@ -123,28 +186,37 @@ func (g *redSpell) Update(b *Guard) {
 		return
 	}
 	g.frame++
-	boss, targ := b.unit, g.target
+	if !g.lost {
+		g.pos = g.target.Pos()
+	}
+	boss, targ := b.unit, g.pos
 
 	// If the spell is charging, show a ray effect between the boss that the target.
 	if g.frame < RedCharge*ns4.FrameRate() {
 		ns4.Effect(effect.GREATER_HEAL, boss, targ)
 		ns4.Effect(effect.GREATER_HEAL, targ, boss)
+		// Show where the flame line will be.
+		if g.tg == nil {
+			g.tg = b.s.TelegraphLine(boss.Pos(), targ, RedCharge)
+		} else {
+			g.tg.SetLine(boss.Pos(), targ)
+		}
 		return
 	}
 	// Initialize the flame line if not done already.
 	if g.line == nil {
 		for i := 0; i < RedLineCnt; i++ {
-			flame := ns4.CreateObject(RedLineModel, boss)
+			flame := b.s.objs.Create(b.owner(), RedLineModel, boss)
 			flame.SetOwner(boss)
 			g.line = append(g.line, flame)
 		}
 	}
 	// Initialize strong target effect, if not done already.
 	if g.strong[0] == nil {
-		g.strong[0] = ns4.CreateObject("LargeFlame", boss)
-		g.strong[1] = ns4.CreateObject("Flame", boss)
-		g.strong[2] = ns4.CreateObject("MediumFlame", boss)
-		g.strong[3] = ns4.CreateObject("SmallFlame", boss)
+		g.strong[0] = b.s.objs.Create(b.owner(), "LargeFlame", boss)
+		g.strong[1] = b.s.objs.Create(b.owner(), "Flame", boss)
+		g.strong[2] = b.s.objs.Create(b.owner(), "MediumFlame", boss)
+		g.strong[3] = b.s.objs.Create(b.owner(), "SmallFlame", boss)
 		for _, a := range g.strong {
 			a.SetOwner(boss)
 		}
@@ -152,13 +224,13 @@ func (g *redSpell) Update(b *Guard) {
 	// Initialize weak target effect, if not done already.
 	if g.weak[0] == nil {
 		for i := range g.weak {
-			g.weak[i] = ns4.CreateObject(RedTargetWeakModel, boss)
+			g.weak[i] = b.s.objs.Create(b.owner(), RedTargetWeakModel, boss)
 			g.weak[i].SetOwner(boss)
 		}
 	}
 
 	// Calculate the distance and direction vector between the boss and the target.
-	p1, p2 := boss.Pos(), targ.Pos()
+	p1, p2 := boss.Pos(), targ
 	vec := p2.Sub(p1)
 	dir := vec.Normalize()
 	dist := float32(vec.Len())
@@ -247,7 +319,8 @@ func (g *redSpell) Update(b *Guard) {
 	}
 
 	// If the distance between boss and the player is large enough - slowly reduce the target effect.
-	if dist > RedTargetMinDist {
+	// Spells that lost the target always wear off.
+	if dist > RedTargetMinDist || g.lost {
 		g.reduce++
 	}
 }
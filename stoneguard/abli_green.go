@@ -51,19 +51,55 @@ func (g *GreenAbility) Update(b *Guard) {
 	}
 	// ability charged - create new spell and reset charge
 	g.charge = 0
+	g.Cast(b, nil)
+}
 
+// Cast a new Green spell. The projectile will be aimed at a given target, or at a random player if target is nil.
+func (g *GreenAbility) Cast(b *Guard, targ ns4.Obj) {
+	// make sure the spell will be updated, even if cast before GreenAfter
+	if g.frame < GreenAfter*ns4.FrameRate() {
+		g.frame = GreenAfter * ns4.FrameRate()
+	}
 	b.unit.AggressionLevel(0)
 	b.unit.WalkTo(b.unit.Pos())
-	charge := ns4.CreateObject("ForceOfNatureCharge", b.unit.Pos())
+	charge := b.s.objs.Create(b.owner(), "ForceOfNatureCharge", b.unit.Pos())
 	charge.SetOwner(b.unit)
+	b.startCast(tr(msgAbilityGreen), GreenCharge)
 
 	// add new spell to active ones
-	g.active = append(g.active, &greenSpell{charge: charge})
+	g.active = append(g.active, &greenSpell{s: b.s, charge: charge, target: targ})
 	g.notFirst = true
 }
 
+// TargetLost handles charging Green spells aimed at a unit that left the fight, according to GreenTargetLost.
+func (g *GreenAbility) TargetLost(b *Guard, u ns4.Obj) {
+	for _, a := range g.active {
+		if a.stop || a.proj != nil || a.target != u {
+			continue
+		}
+		switch GreenTargetLost {
+		case TargetRetarget:
+			// the projectile will pick a random target when launched
+			a.target = nil
+		case TargetFizzle:
+			if a.charge != nil {
+				b.stopCast()
+				b.unit.AggressionLevel(BossAggression)
+			}
+			a.stop = true
+		case TargetFinish:
+			a.target = nil
+			a.lost = true
+		}
+	}
+}
+
 // greenSpell stores state of a single Green spell.
 type greenSpell struct {
+	s       *State
+	target  ns4.Obj    // optional target selected when the spell was cast
+	targPos ns4.Pointf // last known position of the target
+	lost    bool       // target left the fight, projectile will be aimed at the last known position
 	charge  ns4.Obj
 	proj    ns4.Obj
 	ball    ns4.Obj
@@ -74,18 +110,18 @@ type greenSpell struct {
 	stop    bool
 }
 
-// Delete a single Blue spell.
+// Delete a single Green spell.
 func (g *greenSpell) Delete() {
 	if g.charge != nil {
-		g.charge.Delete()
+		g.s.objs.Delete(g.charge)
 		g.charge = nil
 	}
 	if g.ball != nil {
-		g.ball.Delete()
+		g.s.objs.Delete(g.ball)
 		g.ball = nil
 	}
 	if g.proj != nil {
-		g.proj.Delete()
+		g.s.objs.Delete(g.proj)
 		g.proj = nil
 	}
 }
@@ -97,36 +133,42 @@ func (g *greenSpell) Update(b *Guard) {
 	}
 	g.frame++
 	boss := b.unit
+	if g.target != nil {
+		g.targPos = g.target.Pos()
+	}
 	if g.frame < GreenCharge*ns4.FrameRate() {
 		return
 	}
 	if g.charge != nil {
-		g.charge.Delete()
+		b.s.objs.Delete(g.charge)
 		g.charge = nil
 		b.unit.AggressionLevel(BossAggression)
 	}
 	if g.ball != nil && g.ball.Flags().HasAny(object.FlagDead|object.FlagDestroyed) {
+		// the ball exploded on its own
+		b.s.objs.Forget(g.ball)
 		g.ball = nil
 		g.proj.Enable(true)
 		ns4.CastSpell(spell.TOXIC_CLOUD, g.pos, g.pos)
 	}
 	if g.proj == nil {
-		// pick random player in boss room
-		var players []ns4.Obj
-		b.s.EachPlayerInRoom(func(u ns4.Obj) {
-			players = append(players, u)
-		})
-		if len(players) == 0 {
+		// pick random target in boss room
+		rnd := b.s.randomTarget(b.color, nil)
+		if rnd == nil {
 			g.stop = true
 			return // no players in room
 		}
-		ind := ns4.Random(0, len(players)-1)
-		targ := players[ind].Pos()
+		targ := rnd.Pos()
+		if g.target != nil && g.target.CurrentHealth() > 0 {
+			targ = g.target.Pos()
+		} else if g.lost {
+			targ = g.targPos
+		}
 
 		g.pos = boss.Pos()
 		g.vec = targ.Sub(boss.Pos()).Normalize()
 
-		g.proj = ns4.CreateObject(GreenProjModel, g.pos)
+		g.proj = b.s.objs.Create(b.owner(), GreenProjModel, g.pos)
 		if g.proj == nil {
 			panic("cannot create!")
 		}
@@ -172,7 +214,7 @@ func (g *greenSpell) Update(b *Guard) {
 		}
 
 		g.proj.Enable(false)
-		g.ball = ns4.CreateObject("DeathBall", g.pos)
+		g.ball = b.s.objs.Create(b.owner(), "DeathBall", g.pos)
 		g.ball.SetOwner(b.unit)
 	}
 }
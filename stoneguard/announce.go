@@ -0,0 +1,204 @@
+package stoneguard
+
+// This file contains raid warnings and announcements shown to the fight participants.
+
+import (
+	"fmt"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+// AnnounceLang selects the language of announcements.
+var AnnounceLang = "en"
+
+// Priority is a priority of the announcement.
+// Higher priority messages are shown even if lower priority messages were shown recently.
+type Priority int
+
+const (
+	PriorityLow = Priority(iota)
+	PriorityNormal
+	PriorityHigh
+)
+
+// msgID is an ID of the announcement message in the strings table.
+type msgID string
+
+const (
+	msgGuardRed         = msgID("guard_red")
+	msgGuardGreen       = msgID("guard_green")
+	msgGuardBlue        = msgID("guard_blue")
+	msgEffectSwitch     = msgID("effect_switch")
+	msgEffectTimeout    = msgID("effect_timeout")
+	msgExplosionSoon    = msgID("explosion_soon")
+	msgExplosion        = msgID("explosion")
+	msgTargets          = msgID("targets")
+	msgTargetsYou       = msgID("targets_you")
+	msgEffectNameRed    = msgID("effect_red")
+	msgEffectNameGreen  = msgID("effect_green")
+	msgEffectNameBlue   = msgID("effect_blue")
+	msgGuardUnknownName = msgID("guard_unknown")
+	msgAbilityRed       = msgID("ability_red")
+	msgAbilityGreen     = msgID("ability_green")
+	msgAbilityBlue      = msgID("ability_blue")
+	msgFightInProgress  = msgID("fight_in_progress")
+)
+
+// announceStrings contains localized announcement strings for each language.
+var announceStrings = map[string]map[msgID]string{
+	"en": {
+		msgGuardRed:         "Jasper Guardian",
+		msgGuardGreen:       "Jade Guardian",
+		msgGuardBlue:        "Cobalt Guardian",
+		msgGuardUnknownName: "Stone Guardian",
+		msgEffectNameRed:    "jasper",
+		msgEffectNameGreen:  "jade",
+		msgEffectNameBlue:   "cobalt",
+		msgEffectSwitch:     "The room is filled with %s energy!",
+		msgEffectTimeout:    "The energy goes out of control!",
+		msgExplosionSoon:    "%s is about to overload!",
+		msgExplosion:        "%s overloads!",
+		msgTargets:          "%s targets %s!",
+		msgTargetsYou:       "%s targets you!",
+		msgAbilityRed:       "Jasper Chains",
+		msgAbilityGreen:     "Jade Shards",
+		msgAbilityBlue:      "Cobalt Mine",
+		msgFightInProgress:  "The fight is in progress, wait for it to end.",
+	},
+	"ru": {
+		msgGuardRed:         "Яшмовый страж",
+		msgGuardGreen:       "Нефритовый страж",
+		msgGuardBlue:        "Кобальтовый страж",
+		msgGuardUnknownName: "Каменный страж",
+		msgEffectNameRed:    "яшмовой",
+		msgEffectNameGreen:  "нефритовой",
+		msgEffectNameBlue:   "кобальтовой",
+		msgEffectSwitch:     "Зал наполняется %s энергией!",
+		msgEffectTimeout:    "Энергия выходит из-под контроля!",
+		msgExplosionSoon:    "%s скоро перегрузится!",
+		msgExplosion:        "%s перегружается!",
+		msgTargets:          "%s выбирает целью %s!",
+		msgTargetsYou:       "%s выбирает целью вас!",
+		msgAbilityRed:       "Яшмовые цепи",
+		msgAbilityGreen:     "Нефритовые осколки",
+		msgAbilityBlue:      "Кобальтовая мина",
+		msgFightInProgress:  "Идёт бой, дождитесь его окончания.",
+	},
+}
+
+// tr returns a localized announcement string with a given ID and arguments.
+func tr(id msgID, args ...any) string {
+	str, ok := announceStrings[AnnounceLang][id]
+	if !ok {
+		str, ok = announceStrings["en"][id]
+	}
+	if !ok {
+		str = string(id)
+	}
+	if len(args) == 0 {
+		return str
+	}
+	return fmt.Sprintf(str, args...)
+}
+
+// GuardName returns a localized name of the guard with a given color/element.
+func (c Element) GuardName() string {
+	switch c {
+	case Red:
+		return tr(msgGuardRed)
+	case Green:
+		return tr(msgGuardGreen)
+	case Blue:
+		return tr(msgGuardBlue)
+	}
+	return tr(msgGuardUnknownName)
+}
+
+// EffectName returns a localized name of the room effect with a given color/element.
+func (c Element) EffectName() string {
+	switch c {
+	case Red:
+		return tr(msgEffectNameRed)
+	case Green:
+		return tr(msgEffectNameGreen)
+	case Blue:
+		return tr(msgEffectNameBlue)
+	}
+	return c.String()
+}
+
+// announcer stores throttling state for announcements.
+type announcer struct {
+	lastFrame int             // frame of the last broadcast announcement
+	lastPrio  Priority        // priority of the last broadcast announcement
+	lastID    msgID           // ID of the last broadcast announcement
+	lastByMsg map[string]int  // frame of the last announcement with a given text (ID and arguments)
+	lastByObj map[ns4.Obj]int // frame of the last targeted announcement for a given player
+}
+
+// allow checks throttling rules and records the announcement if it's allowed.
+// High priority messages are never throttled. Different messages sent in the same frame are all shown.
+func (a *announcer) allow(prio Priority, id msgID, msg string) bool {
+	now := ns4.Frame()
+	if prio != PriorityHigh {
+		if last, ok := a.lastByMsg[msg]; ok && now-last >= 0 && now-last < AnnounceRepeat*ns4.FrameRate() {
+			return false
+		}
+		sameFrame := now == a.lastFrame && id != a.lastID
+		if df := now - a.lastFrame; !sameFrame && a.lastFrame != 0 && df >= 0 && df < AnnounceInterval*ns4.FrameRate() && prio <= a.lastPrio {
+			return false
+		}
+	}
+	if a.lastByMsg == nil {
+		a.lastByMsg = make(map[string]int)
+	}
+	a.lastByMsg[msg] = now
+	a.lastFrame = now
+	a.lastPrio = prio
+	a.lastID = id
+	return true
+}
+
+// Announce shows a message to all fight participants.
+func (s *State) Announce(prio Priority, id msgID, args ...any) {
+	msg := tr(id, args...)
+	if !s.ann.allow(prio, id, msg) {
+		return
+	}
+	if Debug {
+		fmt.Println(msg)
+	}
+	for _, pl := range ns4.Players() {
+		if s.InRoom(pl.Unit()) {
+			pl.PrintStr(msg)
+		}
+	}
+}
+
+// AnnounceTo shows a message to a single player.
+func (s *State) AnnounceTo(u ns4.Obj, prio Priority, id msgID, args ...any) {
+	pl := u.Player()
+	if pl == nil {
+		return
+	}
+	now := ns4.Frame()
+	if last, ok := s.ann.lastByObj[u]; ok && prio != PriorityHigh && now-last >= 0 && now-last < AnnounceInterval*ns4.FrameRate() {
+		return
+	}
+	if s.ann.lastByObj == nil {
+		s.ann.lastByObj = make(map[ns4.Obj]int)
+	}
+	s.ann.lastByObj[u] = now
+	pl.PrintStr(tr(id, args...))
+}
+
+// playerName returns a name of the player unit. For other units, the type name is returned.
+func playerName(u ns4.Obj) string {
+	if pl := u.Player(); pl != nil {
+		return pl.Name()
+	}
+	if typ := u.Type(); typ != nil {
+		return typ.Name()
+	}
+	return "?"
+}
@@ -2,10 +2,19 @@ package stoneguard
 
 // This file contains all important constants that influence boss balance.
 
+import (
+	"github.com/noxworld-dev/noxscript/ns/v4/enchant"
+	"github.com/noxworld-dev/noxscript/ns/v4/spell"
+)
+
 // Debug enables debugging mode for the boss.
 // This may change boss behavior to help test it faster and/or enable debug messages.
 var Debug = true
 
+// DebugOverlay draws hidden radii and zones of the encounter while Debug is enabled.
+// It creates a lot of marker objects, so it is disabled by default. Use the "sg overlay" command to toggle it.
+var DebugOverlay = false
+
 // General boss balance.
 const (
 	// BossModel is a unit model for the boss.
@@ -43,6 +52,230 @@ const (
 	EnergyExplosionDamage = 20
 	// EnergyExplosionDamageWeak is damage dealt by elemental explosion (when room effect matches).
 	EnergyExplosionDamageWeak = 2
+
+	// EnergyFlashBefore sets how long before the explosion the energy bar starts to flash.
+	EnergyFlashBefore = 5 // sec
+	// EnergyFlashInterval sets how frequently the energy bar flashes.
+	EnergyFlashInterval = 8 // frames
+)
+
+// Debug overlay values.
+const (
+	// OverlayInterval sets how frequently the debug overlay is redrawn.
+	OverlayInterval = 5 // frames
+	// OverlaySpacing sets a distance between debug overlay markers.
+	OverlaySpacing = 30
+	// OverlayGuardModel sets an object model for guard radii (EnergyDist, BossStartFightDist).
+	OverlayGuardModel = "HealOrb"
+	// OverlayAbilityModel sets an object model for radii of active spells.
+	OverlayAbilityModel = "DrainManaOrb"
+	// OverlayRoomModel sets an object model for the room boundaries.
+	OverlayRoomModel = "WhiteOrb"
+)
+
+// Debuff marker balance values.
+const (
+	// MarkerR sets a radius at which debuff markers orbit the player.
+	MarkerR = 18
+	// MarkerHeight sets how high above the player debuff markers are shown.
+	MarkerHeight = 34
+	// MarkerSpeed sets a spin speed of debuff markers.
+	MarkerSpeed = 0.1
+	// MarkerRedTargetModel sets an object model for the Red ability target marker.
+	MarkerRedTargetModel = "DrainManaOrb"
+	// MarkerStunnedModel sets an object model for the stun marker.
+	MarkerStunnedModel = "WhiteOrb"
+)
+
+// RaidFrameMarkerInterval sets how frequently the room effect marker on the raid frame is redrawn.
+const RaidFrameMarkerInterval = 8 // frames
+
+// CommandPrefix is a chat prefix for debug commands.
+const CommandPrefix = "sg"
+
+// Pull balance values.
+const (
+	// PullReadyCheck enables the ready-check before the fight.
+	// If enabled, the fight will only start after PullCommand or the lever, and a countdown.
+	// Otherwise, it starts immediately when players come closer than BossStartFightDist.
+	PullReadyCheck = false
+	// PullCommand is a chat command that starts the ready-check.
+	PullCommand = "!pull"
+	// PullLever creates a lever near the entrance that starts the ready-check when switched.
+	PullLever = true
+	// PullLeverModel sets an object model for the ready-check lever.
+	PullLeverModel = "Lever"
+	// PullCountdown is a duration of the countdown before the fight starts.
+	PullCountdown = 5 // sec
+	// PullWarnInterval sets how frequently players are warned when they come too close to the bosses.
+	PullWarnInterval = 3 // sec
+	// PullOptOutCommand is a chat command that toggles whether the player takes part in the next pulls.
+	PullOptOutCommand = "!optout"
+)
+
+// Room effect pattern balance values.
+const (
+	// PatternSweepSpeed sets how fast the sweeping wall moves along the room diagonal.
+	PatternSweepSpeed = 3
+	// PatternSweepWidth sets how far from the sweeping wall players are affected.
+	PatternSweepWidth = 46
+
+	// PatternRingSpeed sets how fast the rings expand from the room center.
+	PatternRingSpeed = 2
+	// PatternRingWidth sets how far from the ring players are affected.
+	PatternRingWidth = 34
+	// PatternRingSegments sets a number of segments in the ring.
+	PatternRingSegments = 12
+
+	// PatternCheckerTile sets the size of the checkerboard tile.
+	PatternCheckerTile = 92
+	// PatternCheckerPeriod sets how frequently the active checkerboard tiles switch.
+	PatternCheckerPeriod = 4 // sec
+
+	// PatternSpiralArms sets a number of spiral arms.
+	PatternSpiralArms = 4
+	// PatternSpiralSpeed sets a rotation speed of spiral arms.
+	PatternSpiralSpeed = 0.02
+	// PatternSpiralWidth sets how far from the spiral arm players are affected.
+	PatternSpiralWidth = 46
+)
+
+// Petrification balance values.
+const (
+	// PetrifyMax is a value of the petrification meter at which the player dies.
+	PetrifyMax = 100
+	// PetrifyRate sets how much the meter increases each RoomEffectTickInterval for each room effect power level.
+	PetrifyRate = 1
+	// PetrifyStep sets the meter value for each slow level.
+	PetrifyStep = 20
+	// PetrifySpeedStep sets a percent of player speed lost on each slow level.
+	PetrifySpeedStep = 15
+)
+
+// Announcement balance values.
+const (
+	// AnnounceInterval sets a minimal interval between announcements of the same or lower priority.
+	AnnounceInterval = 2 // sec
+	// AnnounceRepeat sets a minimal interval between announcements with the same text. High priority messages ignore it.
+	AnnounceRepeat = 5 // sec
+	// AnnounceExplosionSoon sets how long before the explosion players will be warned about it.
+	AnnounceExplosionSoon = 5 // sec
+)
+
+// Threat balance values.
+const (
+	// ThreatPerDamage sets how much threat is generated per point of damage dealt to the shared health pool.
+	ThreatPerDamage = 10
+	// ThreatSwitchPerc sets a percent of current target threat another player must reach to pull the guard away.
+	ThreatSwitchPerc = 110
+	// ThreatUpdateInterval sets how frequently the guard will reconsider its target.
+	ThreatUpdateInterval = 10 // frames
+	// ThreatAttack makes the guard attack the player with the top threat.
+	// If disabled, threat is still tracked, but the built-in AI selects the target.
+	ThreatAttack = true
+
+	// TauntSpell is a spell that players can cast on the guard to taunt it.
+	TauntSpell = spell.CONFUSE
+	// TauntEnchant is an enchant applied by TauntSpell. Guard will detect taunts by it and remove it immediately.
+	TauntEnchant = enchant.CONFUSED
+	// ThreatTauntBonus sets how much threat above the current top the taunting player will get.
+	ThreatTauntBonus = 100
+	// ThreatTauntDur sets how long the guard will be forced to attack the taunting player.
+	ThreatTauntDur = 6 // sec
+	// TauntManaCost sets how much mana a player must spend at once to be considered the caster of TauntSpell.
+	// It must not exceed the mana cost of TauntSpell. Only one player attacking the guard may match, otherwise the taunt is ignored.
+	TauntManaCost = 10
+	// TauntCastWindow sets how long after spending the mana the player can be credited with the taunt.
+	TauntCastWindow = 5 // frames
+)
+
+// Steering balance values.
+const (
+	// SteerEnabled enables the steering behaviours. If disabled, guards are moved by the built-in AI only.
+	SteerEnabled = true
+	// SteerInterval sets how frequently the guard movement will be adjusted.
+	SteerInterval = 15 // frames
+	// SteerStep sets how far ahead the guard will walk on each steering update.
+	SteerStep = 46
+	// SteerMinForce sets a minimal combined steering force that overrides the built-in AI.
+	SteerMinForce = 0.5
+
+	// SteerTankWeight sets a weight of "stay near tank" behaviour.
+	SteerTankWeight = 1.0
+	// SteerTankDist sets a distance from the threat target at which the guard starts to follow it.
+	SteerTankDist = 92
+
+	// SteerHomeWeight sets a weight of "return to spawn when untargeted" behaviour.
+	SteerHomeWeight = 1.0
+	// SteerHomeDist sets a distance from the spawn point at which the guard is considered home.
+	SteerHomeDist = 23
+
+	// SteerSeekWeight sets a weight of "seek another guard" behaviour.
+	SteerSeekWeight = 0.3
+
+	// SteerLeashWeight sets a weight of "stay in the room" behaviour.
+	SteerLeashWeight = 2.0
+	// SteerLeashMargin sets a distance from the room walls at which the guard will turn back.
+	SteerLeashMargin = 46
+)
+
+// Wipe recovery balance values.
+const (
+	// WipeRestore restores health and mana of participants when they are moved out after a wipe.
+	WipeRestore = true
+	// WipeGraveyardRespawn moves dead participants to the graveyard once they respawn.
+	WipeGraveyardRespawn = true
+)
+
+// Leash balance values.
+const (
+	// LeashTolerance sets how far behind the room walls the guard can go before it starts evading.
+	LeashTolerance = 23
+	// LeashHealPerc sets a percent of BossHealth that is restored to the shared pool when a guard evades.
+	LeashHealPerc = 10
+	// LeashEvadeTimeout sets a maximal duration of the evade, after which the guard is teleported to spawn.
+	LeashEvadeTimeout = 8 // sec
+	// LeashOutLimit sets how long any guard can stay out of the room before the encounter is reset.
+	// It prevents players from keeping some of the guards out and fighting the rest alone.
+	LeashOutLimit = 4 // sec
+)
+
+// Player creature balance values. Creatures are summons and charmed monsters owned by participants.
+const (
+	// CreaturesTargeted allows guard abilities to select player creatures as targets.
+	CreaturesTargeted = true
+	// CreaturesExplosionDamage makes energy explosions damage player creatures.
+	CreaturesExplosionDamage = true
+	// CreaturesDispelOnPull removes all player creatures from the room when the fight starts.
+	CreaturesDispelOnPull = false
+	// CreaturesMax sets how many player creatures are allowed in the room. Extra ones are moved out.
+	CreaturesMax = 4
+	// CreaturesCheckInterval sets how frequently the creature cap is checked.
+	CreaturesCheckInterval = 1 // sec
+)
+
+// Anti-exploit balance values.
+const (
+	// ExploitInvulnerableDur sets how long the guard stays invulnerable when a rule responds with invulnerability.
+	ExploitInvulnerableDur = 1 // sec
+	// ExploitDeathBallR is a radius around the guard in which player Force of Nature balls are removed.
+	ExploitDeathBallR = 46
+	// ExploitDeathBallReflect reflects Force of Nature balls back to players instead of deleting them.
+	ExploitDeathBallReflect = false
+	// ExploitDeathBallForce is a force applied to the reflected Force of Nature ball.
+	ExploitDeathBallForce = 20
+	// ExploitFlamesCooldown sets how frequently the guard will put out the flames under it.
+	ExploitFlamesCooldown = 15 // frames
+	// ExploitSlowLimit sets how long the guard tolerates the slow enchant.
+	ExploitSlowLimit = 90 // frames
+	// ExploitPushDist is a distance per frame above which the guard movement is considered to be caused by pull/push.
+	ExploitPushDist = 10
+	// ExploitKnockbackGrace sets how long after a guard explosion the guard displacement is not considered an exploit.
+	ExploitKnockbackGrace = 15 // frames
+	// ExploitHarpoonR is a radius around the guard in which player harpoons are removed.
+	ExploitHarpoonR = 46
+	// ExploitInvisibleLimit sets how long players in the room can stay invisible during the fight.
+	ExploitInvisibleLimit = 60 // frames
 )
 
 // Room effect balance values.
@@ -60,6 +293,15 @@ const (
 	RoomEffectPowerInterval = 15 // sec
 	// RoomEffectPowerReport changes the interval at which current effect power will be printed to console.
 	RoomEffectPowerReport = 5 // sec
+
+	// RoomEffectTickInterval sets how frequently the room effect debuff is applied to players.
+	RoomEffectTickInterval = 1 // sec
+	// RoomRedBurnDamage sets burn damage per tick for each power level of the Red room effect.
+	RoomRedBurnDamage = 1
+	// RoomGreenPoisonDamage sets poison damage per tick for each power level above zero of the Green room effect.
+	RoomGreenPoisonDamage = 1
+	// RoomBlueManaDrain sets mana drained per tick for each power level of the Blue room effect.
+	RoomBlueManaDrain = 5
 )
 
 const (
@@ -73,6 +315,16 @@ const (
 	DemoBossUnfreeze = 8 // sec
 )
 
+// Telegraph balance values.
+const (
+	// TelegraphModel sets an object model for ground telegraph markers.
+	TelegraphModel = "WhiteOrb"
+	// TelegraphSpacing sets a distance between telegraph markers.
+	TelegraphSpacing = 23
+	// TelegraphBlink sets how long before the ability resolves the telegraph starts to blink.
+	TelegraphBlink = 1 // sec
+)
+
 // Red ability balance values.
 const (
 	// RedCooldown sets how frequently the boss will cast the Red ability.
@@ -83,6 +335,8 @@ const (
 	RedAfter = 26
 	// RedOnlyOne limits Red ability to a single target.
 	RedOnlyOne = true
+	// RedTargetLost sets what the Red spell does when its target dies or disconnects.
+	RedTargetLost = TargetRetarget
 
 	// RedLineCnt sets a number of flames between the boss and the target.
 	RedLineCnt = 3
@@ -113,6 +367,9 @@ const (
 	BlueCharge = 4 // sec
 	// BlueAfter sets a delay before the first Blue ability is fired. After that, it will fire according to BlueCooldown.
 	BlueAfter = 10
+	// BlueTargetLost sets what the charging Blue spell does when its target dies or disconnects.
+	// Once the spell lands, it no longer depends on the target.
+	BlueTargetLost = TargetFinish
 
 	// BlueDangerModel is a model that indicates a danger of a Blue spell area.
 	BlueDangerModel = "BlueFlame"
@@ -154,6 +411,9 @@ const (
 	GreenAfter = 42
 	// GreenCharge sets how long it will take for Green ability to charge (FoN effect to projectile).
 	GreenCharge = 4 // sec
+	// GreenTargetLost sets what the charging Green spell does when its selected target dies or disconnects.
+	// Retarget aims the projectile at a random unit in the room, finish aims it at the last known target position.
+	GreenTargetLost = TargetRetarget
 
 	// GreenProjMax sets maximal amount of Green spell projectiles.
 	GreenProjMax = 4
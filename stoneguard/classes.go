@@ -0,0 +1,84 @@
+package stoneguard
+
+// This file contains per-class modifiers, which allow the encounter mechanics to prefer or adapt to Nox classes.
+
+import (
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+	"github.com/noxworld-dev/opennox-lib/player"
+)
+
+// ClassMod is a set of encounter modifiers for a single player class.
+type ClassMod struct {
+	// TargetWeight sets how likely the guard ability of a given color/element selects this class as a target.
+	// Zero weight means the class is never selected, unless there are no other targets.
+	TargetWeight [colorMax]int
+	// BlueSlow makes the Blue room effect slow the unit instead of draining mana.
+	BlueSlow bool
+}
+
+// ClassMods is a per-class modifier table.
+var ClassMods = map[player.Class]ClassMod{
+	// Warriors have no mana, so Blue room effect slows them instead.
+	player.Warrior: {
+		TargetWeight: [colorMax]int{Red: 1, Green: 1, Blue: 2},
+		BlueSlow:     true,
+	},
+	// Red ability prefers ranged casters.
+	player.Wizard: {
+		TargetWeight: [colorMax]int{Red: 3, Green: 1, Blue: 1},
+	},
+	player.Conjurer: {
+		TargetWeight: [colorMax]int{Red: 3, Green: 1, Blue: 1},
+	},
+}
+
+// CreatureMod is a modifier for units that are not players, for example player creatures.
+var CreatureMod = ClassMod{
+	TargetWeight: [colorMax]int{Red: 1, Green: 1, Blue: 1},
+	BlueSlow:     true,
+}
+
+// classMod returns modifiers for a given unit.
+func classMod(u ns4.Obj) ClassMod {
+	if u.Player() == nil {
+		return CreatureMod
+	}
+	if m, ok := ClassMods[u.GetClass()]; ok {
+		return m
+	}
+	return CreatureMod
+}
+
+// randomTarget selects a random target in the room for the ability of a given color/element, weighted by class.
+// Units for which skip returns true are ignored. It returns nil if there are no targets.
+func (s *State) randomTarget(c Element, skip func(u ns4.Obj) bool) ns4.Obj {
+	var (
+		list    []ns4.Obj
+		weights []int
+		total   int
+	)
+	s.EachTargetInRoom(func(u ns4.Obj) {
+		if skip != nil && skip(u) {
+			return
+		}
+		w := classMod(u).TargetWeight[c]
+		list = append(list, u)
+		weights = append(weights, w)
+		total += w
+	})
+	if len(list) == 0 {
+		return nil
+	}
+	if total <= 0 {
+		// only zero-weight targets left
+		return list[ns4.Random(0, len(list)-1)]
+	}
+	v := ns4.Random(0, total-1)
+	for i, w := range weights {
+		if v < w {
+			return list[i]
+		}
+		v -= w
+	}
+	return list[len(list)-1]
+}
@@ -0,0 +1,188 @@
+package stoneguard
+
+// This file contains debug chat commands for the encounter.
+// Commands are only available to the host, or to everyone in Debug mode.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+	"github.com/noxworld-dev/noxscript/ns/v4/enchant"
+)
+
+// onCommand parses and runs debug commands starting with CommandPrefix.
+func (s *State) onCommand(t ns4.Team, p ns4.Player, obj ns4.Obj, msg string) string {
+	args := strings.Fields(msg)
+	if p == nil || len(args) == 0 || args[0] != CommandPrefix {
+		return msg
+	}
+	if !Debug && !isHost(p) {
+		p.PrintStr("Debug commands are only available to the host.")
+		return ""
+	}
+	if len(args) < 2 {
+		p.PrintStr("usage: sg start|reset|health|effect|cast|energy|freeze|overlay|status|objects")
+		return ""
+	}
+	if err := s.runCommand(p, args[1], args[2:]); err != nil {
+		p.PrintStr(err.Error())
+	}
+	return "" // do not show commands in chat
+}
+
+// isHost checks if the player is the host.
+func isHost(p ns4.Player) bool {
+	host := ns4.HostPlayer()
+	return host != nil && host.Unit() == p.Unit()
+}
+
+// runCommand runs a single debug command.
+func (s *State) runCommand(p ns4.Player, cmd string, args []string) error {
+	switch cmd {
+	case "start":
+		if s.state != BossWaiting && s.state != BossCountdown {
+			return fmt.Errorf("the fight is already started")
+		}
+		s.startFight()
+	case "reset":
+		s.Reset()
+	case "health":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: sg health <value>")
+		}
+		v, err := strconv.Atoi(args[0])
+		if err != nil || v <= 0 {
+			return fmt.Errorf("invalid health: %q", args[0])
+		}
+		if v > BossHealth {
+			v = BossHealth
+		}
+		s.health = v
+	case "effect":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: sg effect red|green|blue")
+		}
+		e, ok := parseElement(args[0])
+		if !ok {
+			return fmt.Errorf("unknown effect: %q", args[0])
+		}
+		if s.state != BossFighting {
+			return fmt.Errorf("the fight is not started")
+		}
+		s.setRoomEffect(e)
+	case "cast":
+		if len(args) < 1 || len(args) > 2 {
+			return fmt.Errorf("usage: sg cast red|green|blue [player]")
+		}
+		g, err := s.guardByName(args[0])
+		if err != nil {
+			return err
+		}
+		var targ ns4.Obj
+		if len(args) > 1 {
+			targ = playerByName(args[1])
+			if targ == nil {
+				return fmt.Errorf("unknown player: %q", args[1])
+			}
+		}
+		if g.abil == nil {
+			return fmt.Errorf("guard %s has no ability", g.color)
+		}
+		g.abil.Cast(g, targ)
+	case "energy":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: sg energy red|green|blue <value>")
+		}
+		g, err := s.guardByName(args[0])
+		if err != nil {
+			return err
+		}
+		v, err := strconv.Atoi(args[1])
+		if err != nil || v < 0 {
+			return fmt.Errorf("invalid energy: %q", args[1])
+		}
+		g.energy = v
+	case "freeze":
+		s.frozen = !s.frozen
+		for _, g := range s.bosses {
+			g.unit.Freeze(s.frozen)
+			if s.frozen {
+				g.unit.Enchant(enchant.FREEZE, ns4.Infinite())
+			} else {
+				g.unit.EnchantOff(enchant.FREEZE)
+			}
+		}
+	case "overlay":
+		DebugOverlay = !DebugOverlay
+	case "status":
+		for _, line := range s.statusLines() {
+			p.PrintStr(line)
+		}
+	case "objects":
+		lines := append(s.objs.Report(), demoState.objs.Report()...)
+		if len(lines) == 0 {
+			p.PrintStr("no live objects")
+		}
+		for _, line := range lines {
+			p.PrintStr(line)
+		}
+	default:
+		return fmt.Errorf("unknown command: %q", cmd)
+	}
+	return nil
+}
+
+// guardByName finds a guard by its color/element name.
+func (s *State) guardByName(name string) (*Guard, error) {
+	e, ok := parseElement(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown guard: %q", name)
+	}
+	for _, g := range s.bosses {
+		if g.color == e {
+			return g, nil
+		}
+	}
+	return nil, fmt.Errorf("no %s guard", e)
+}
+
+// playerByName finds player unit by name.
+func playerByName(name string) ns4.Obj {
+	for _, pl := range ns4.Players() {
+		if strings.EqualFold(pl.Name(), name) {
+			return pl.Unit()
+		}
+	}
+	return nil
+}
+
+// statusLines returns a human-readable status of the encounter.
+func (s *State) statusLines() []string {
+	states := map[BossState]string{
+		BossWaiting:   "waiting",
+		BossCountdown: "countdown",
+		BossFighting:  "fighting",
+		BossDead:      "dead",
+	}
+	lines := []string{
+		fmt.Sprintf("state: %s, frame: %d, health: %d/%d", states[s.state], s.frame, s.health, BossHealth),
+	}
+	if s.curEffect >= 0 {
+		lines = append(lines, fmt.Sprintf("effect: %s, power: %d, timeout in: %ds",
+			s.curEffect, s.roomEffectPower(), (s.roomEffectTimeout()-(s.frame-s.roomEffectStart))/ns4.FrameRate()))
+	}
+	for _, g := range s.bosses {
+		targ := "none"
+		if g.threat.target != nil {
+			targ = playerName(g.threat.target)
+		}
+		lines = append(lines, fmt.Sprintf("%s: energy %d/%d, target: %s, evading: %v",
+			g.color, g.energy, EnergyExplosionChargeDur, targ, g.evading))
+	}
+	for _, e := range s.roster {
+		lines = append(lines, fmt.Sprintf("%s: %s", e.name, e.status))
+	}
+	return lines
+}
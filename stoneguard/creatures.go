@@ -0,0 +1,75 @@
+package stoneguard
+
+// This file contains the policy for creatures owned by participants: conjurer summons and charmed monsters.
+
+import (
+	"fmt"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+	"github.com/noxworld-dev/opennox-lib/object"
+)
+
+// isGuard checks if the unit is one of the guards.
+func (s *State) isGuard(u ns4.Obj) bool {
+	for _, g := range s.bosses {
+		if g.unit == u {
+			return true
+		}
+	}
+	return false
+}
+
+// EachCreatureInRoom iterates over all alive creatures in the boss room that are owned by players.
+func (s *State) EachCreatureInRoom(fnc func(u ns4.Obj)) {
+	var list []ns4.Obj
+	ns4.FindObjects(func(u ns4.Obj) bool {
+		list = append(list, u)
+		return true
+	},
+		// the rectangle goes first, so the engine only checks objects near the room
+		roomRect,
+		ns4.HasClass(object.ClassMonster),
+		ns4.ObjCondFunc(func(u ns4.Obj) bool {
+			return u.CurrentHealth() > 0 && s.InRoom(u) && !s.isGuard(u) && isPlayerOwned(u)
+		}),
+	)
+	// Call the function after the search, since it may modify objects.
+	for _, u := range list {
+		fnc(u)
+	}
+}
+
+// EachTargetInRoom iterates over all units in the boss room that guard abilities can target.
+// These are alive players and, if CreaturesTargeted is set, player creatures.
+func (s *State) EachTargetInRoom(fnc func(u ns4.Obj)) {
+	s.EachPlayerInRoom(fnc)
+	if CreaturesTargeted {
+		s.EachCreatureInRoom(fnc)
+	}
+}
+
+// dispelCreatures removes all player creatures from the boss room.
+func (s *State) dispelCreatures() {
+	n := 0
+	s.EachCreatureInRoom(func(u ns4.Obj) {
+		u.Delete()
+		n++
+	})
+	if Debug && n != 0 {
+		fmt.Printf("Dispelled %d player creatures\n", n)
+	}
+}
+
+// creatureCapUpdate moves player creatures above CreaturesMax out of the boss room.
+func (s *State) creatureCapUpdate() {
+	if s.frame%(CreaturesCheckInterval*ns4.FrameRate()) != 0 {
+		return
+	}
+	n := 0
+	s.EachCreatureInRoom(func(u ns4.Obj) {
+		n++
+		if n > CreaturesMax {
+			u.SetPos(graveyardPos)
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package stoneguard
+
+import (
+	"testing"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+// posObj is a fake object that only has a position.
+type posObj struct {
+	ns4.Obj
+	pos ns4.Pointf
+}
+
+func (o *posObj) Pos() ns4.Pointf {
+	return o.pos
+}
+
+func TestRoomRect(t *testing.T) {
+	// Creatures are searched in roomRect first, so it must contain the whole room.
+	for x := float32(3800); x <= 5300; x += 10 {
+		for y := float32(3800); y <= 5300; y += 10 {
+			pos := ns4.Ptf(x, y)
+			if inRoomArea(pos, 0) && !roomRect.Matches(&posObj{pos: pos}) {
+				t.Fatalf("room point %v is outside of the search rectangle", pos)
+			}
+		}
+	}
+	for _, pos := range wallPoints {
+		if !roomRect.Matches(&posObj{pos: pos}) {
+			t.Fatalf("room wall %v is outside of the search rectangle", pos)
+		}
+	}
+}
@@ -27,15 +27,12 @@ const (
 	demoWidth = 368
 )
 
+// demoRoom is the demo room area used for the room effects.
+var demoRoom = roomArea{axisStart: demoAxisStart, length: demoLength, width: demoWidth}
+
 // demoState contains all state of the demo scene
 var demoState DemoState
 
-func init() {
-	// register map events
-	ns4.OnMapEvent(ns4.MapInitialize, demoState.Reset)
-	ns4.OnFrame(demoState.Update)
-}
-
 type DemoStatus int
 
 const (
@@ -46,6 +43,7 @@ const (
 )
 
 type DemoState struct {
+	objs    objectRegistry
 	urchins ns4.Objects
 	boss    ns4.Obj
 	shield  ns4.Obj
@@ -55,16 +53,17 @@ type DemoState struct {
 }
 
 func (d *DemoState) Delete() {
-	d.urchins.Delete()
+	d.objs.Delete(d.urchins...)
 	d.urchins = nil
 	if d.boss != nil {
-		d.boss.Delete()
+		d.objs.Delete(d.boss)
 		d.boss = nil
 	}
 	if d.shield != nil {
-		d.shield.Delete()
+		d.objs.Delete(d.shield)
 		d.shield = nil
 	}
+	d.objs.Cleanup("demo")
 }
 
 func (d *DemoState) Reset() {
@@ -72,16 +71,16 @@ func (d *DemoState) Reset() {
 	d.status = DemoWaiting
 	d.frame = -1
 	for _, pos := range urchinPos {
-		obj := ns4.CreateObject("Urchin", pos)
+		obj := d.objs.Create("demo urchins", "Urchin", pos)
 		obj.LookWithAngle(32)
 		d.urchins = append(d.urchins, obj)
 	}
 
-	d.boss = ns4.CreateObject("Urchin", urchinBossPos)
+	d.boss = d.objs.Create("demo boss", "Urchin", urchinBossPos)
 	d.boss.AggressionLevel(0)
 	d.boss.Enchant(enchant.INVULNERABLE, ns4.Infinite())
 
-	d.shield = ns4.CreateObject(EnergyShieldModel, urchinBossPos)
+	d.shield = d.objs.Create("demo boss", EnergyShieldModel, urchinBossPos)
 	d.shield.Freeze(true)
 }
 
@@ -128,7 +127,7 @@ func (d *DemoState) updateEffect() {
 	// Power rises as the time passes.
 	// Due to integer division, it will rise in steps.
 	power := df / (DemoEffectPowerInterval * ns4.FrameRate())
-	drawRoomEffect(d.effect, df, power, demoAxisStart, demoLength, demoWidth)
+	drawRoomEffect(d.effect, df, power, demoRoom)
 }
 
 func (d *DemoState) startBoss() {
@@ -154,7 +153,7 @@ func (d *DemoState) updateBoss() {
 		d.boss.EnchantOff(enchant.INVULNERABLE)
 		ns4.CastSpell(spell.TURN_UNDEAD, d.boss, d.boss)
 		if d.shield != nil {
-			d.shield.Delete()
+			d.objs.Delete(d.shield)
 			d.shield = nil
 		}
 	}
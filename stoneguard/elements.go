@@ -2,7 +2,9 @@ package stoneguard
 
 import (
 	"fmt"
+	"strings"
 
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
 	"github.com/noxworld-dev/noxscript/ns/v4/damage"
 	"github.com/noxworld-dev/noxscript/ns/v4/effect"
 	"github.com/noxworld-dev/noxscript/ns/v4/enchant"
@@ -30,6 +32,16 @@ func (c Element) String() string {
 	return fmt.Sprintf("GuardColor(%d)", int(c))
 }
 
+// parseElement parses color/element name.
+func parseElement(name string) (Element, bool) {
+	for c := Element(0); c < colorMax; c++ {
+		if strings.EqualFold(c.String(), name) {
+			return c, true
+		}
+	}
+	return -1, false
+}
+
 // Enchant returns an enchant that corresponds to the color/element.
 func (c Element) Enchant() enchant.Enchant {
 	switch c {
@@ -70,9 +82,41 @@ func (c Element) DamageType() damage.Type {
 	return damage.ZAP_RAY
 }
 
+// ApplyRoomEffect applies a gameplay debuff of the room effect to a player. Debuff stacks with the effect power.
+func (c Element) ApplyRoomEffect(u ns4.Obj, power int) {
+	lvl := power + 1
+	switch c {
+	case Red:
+		// Red burns over time.
+		u.Damage(nil, RoomRedBurnDamage*lvl, damage.FLAME)
+	case Green:
+		// Green slows, and poisons at higher power.
+		u.Enchant(enchant.SLOWED, ns4.Seconds(RoomEffectTickInterval))
+		if power > 0 {
+			u.Damage(nil, RoomGreenPoisonDamage*power, damage.POISON)
+		}
+	case Blue:
+		// Blue drains mana. Units without mana are slowed instead.
+		if classMod(u).BlueSlow {
+			u.Enchant(enchant.SLOWED, ns4.Seconds(RoomEffectTickInterval))
+			return
+		}
+		mana := u.CurrentMana() - RoomBlueManaDrain*lvl
+		if mana < 0 {
+			mana = 0
+		}
+		u.SetMana(mana)
+	}
+}
+
 // Ability is a unique boss ability.
 type Ability interface {
 	Update(g *Guard)
+	// Cast a new spell immediately. Target may be nil, in which case ability selects one.
+	Cast(g *Guard, targ ns4.Obj)
+	// TargetLost is called when the unit leaves the fight (dies or disconnects).
+	// The ability should handle its spells that target the unit, according to its TargetPolicy.
+	TargetLost(g *Guard, u ns4.Obj)
 	Delete()
 }
 
@@ -0,0 +1,360 @@
+package stoneguard
+
+// This file contains the anti-exploit rule engine. Each rule declares a forbidden or limited player interaction
+// with the guards, how to detect it and how the guard responds to it.
+
+import (
+	"fmt"
+	"strings"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+	"github.com/noxworld-dev/noxscript/ns/v4/enchant"
+	"github.com/noxworld-dev/noxscript/ns/v4/spell"
+)
+
+// ExploitResponse is a set of actions the guard takes when an anti-exploit rule fires.
+type ExploitResponse int
+
+const (
+	// RespondCounterspell casts a counterspell on the guard.
+	RespondCounterspell = ExploitResponse(1 << iota)
+	// RespondInvulnerable makes the guard invulnerable for ExploitInvulnerableDur.
+	RespondInvulnerable
+	// RespondDispel removes the rule enchant from detected objects, or from the guard if there are none.
+	RespondDispel
+	// RespondCleanup removes detected objects, or runs a custom cleanup of the rule.
+	RespondCleanup
+	// RespondReset resets the whole encounter.
+	RespondReset
+)
+
+var exploitResponseNames = []string{"counterspell", "invulnerable", "dispel", "cleanup", "reset"}
+
+// Has checks if the response includes a given action.
+func (r ExploitResponse) Has(r2 ExploitResponse) bool {
+	return r&r2 != 0
+}
+
+func (r ExploitResponse) String() string {
+	var names []string
+	for i, name := range exploitResponseNames {
+		if r.Has(ExploitResponse(1 << i)) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "+")
+}
+
+// ExploitRule declares a forbidden or limited player interaction with the guard.
+type ExploitRule struct {
+	// Name of the rule, used in logs.
+	Name string
+	// Detect checks if the exploit is happening right now for a given guard.
+	// It may also return the objects involved: flames, projectiles or players.
+	Detect func(g *Guard) (bool, ns4.Objects)
+	// DetectRoom is used instead of Detect by rules that do not depend on a specific guard, for example player enchants.
+	// These rules are checked once per frame, and their responses apply to all guards.
+	DetectRoom func(s *State) (bool, ns4.Objects)
+	// Limit is a number of frames the interaction is tolerated before the rule fires. Zero fires immediately.
+	Limit int
+	// Cooldown is a minimal number of frames between two responses of the rule.
+	Cooldown int
+	// Response is a set of actions taken when the rule fires.
+	Response ExploitResponse
+	// Enchant is removed by RespondDispel.
+	Enchant enchant.Enchant
+	// Cleanup is an optional custom action for RespondCleanup. By default, detected objects are deleted.
+	// For room rules it is called for each guard.
+	Cleanup func(g *Guard, objs ns4.Objects)
+}
+
+// ExploitRules is a list of anti-exploit rules of the encounter.
+// Guard rules are checked in order for each guard, room rules are checked in order after all guards are updated.
+var ExploitRules = []ExploitRule{
+	{
+		Name:     "charm",
+		Detect:   detectEnchant(enchant.CHARMING),
+		Response: RespondCounterspell,
+	},
+	{
+		Name:     "charmed",
+		Detect:   detectPlayerOwner,
+		Response: RespondReset,
+	},
+	{
+		// Force of Nature ball deals a lot of damage each frame, while it's stuck in the guard.
+		// The guard is also made invulnerable for a moment, so the damage already dealt by the ball is not repeated.
+		Name:     "force-of-nature",
+		Detect:   detectDeathBall,
+		Response: RespondCleanup | RespondInvulnerable,
+		Cleanup:  removeDeathBalls,
+	},
+	{
+		Name:     "ground-flames",
+		Detect:   detectFlames,
+		Cooldown: ExploitFlamesCooldown,
+		Response: RespondCleanup,
+	},
+	{
+		Name:     "confusion",
+		Detect:   detectConfusion,
+		Response: RespondDispel,
+		Enchant:  enchant.CONFUSED,
+	},
+	{
+		Name:     "slow",
+		Detect:   detectEnchant(enchant.SLOWED),
+		Limit:    ExploitSlowLimit,
+		Response: RespondDispel,
+		Enchant:  enchant.SLOWED,
+	},
+	{
+		Name:     "pull-push",
+		Detect:   detectDisplacement,
+		Response: RespondCleanup,
+		Cleanup:  restorePos,
+	},
+	{
+		Name:     "harpoon",
+		Detect:   detectHarpoon,
+		Response: RespondCleanup,
+	},
+	{
+		Name:       "invisibility",
+		DetectRoom: detectInvisiblePlayers,
+		Limit:      ExploitInvisibleLimit,
+		Response:   RespondDispel,
+		Enchant:    enchant.INVISIBLE,
+	},
+}
+
+// exploitState is a state of a single anti-exploit rule, for a guard or for the whole room.
+type exploitState struct {
+	since int // frame when the exploit was detected first, or -1
+	fired int // frame when the rule fired last time, or -1
+	count int // how many times the rule fired
+}
+
+// exploitStateFor returns the state of the rule, creating it if necessary.
+func exploitStateFor(m map[string]*exploitState, r *ExploitRule) *exploitState {
+	st := m[r.Name]
+	if st == nil {
+		st = &exploitState{since: -1, fired: -1}
+		m[r.Name] = st
+	}
+	return st
+}
+
+// check updates the rule state for the current frame and reports if the rule fires.
+func (st *exploitState) check(r *ExploitRule, frame int, detected bool) bool {
+	if !detected {
+		st.since = -1
+		return false
+	}
+	if st.since < 0 {
+		st.since = frame
+	}
+	if frame-st.since < r.Limit {
+		return false // still tolerated
+	}
+	if st.fired >= 0 && frame-st.fired < r.Cooldown {
+		return false
+	}
+	st.fired = frame
+	st.count++
+	return true
+}
+
+// exploitUpdate checks guard anti-exploit rules and runs responses for the ones that fire.
+func (g *Guard) exploitUpdate() {
+	if g.exploits == nil {
+		g.exploits = make(map[string]*exploitState)
+	}
+	for i := range ExploitRules {
+		r := &ExploitRules[i]
+		if r.Detect == nil {
+			continue
+		}
+		ok, objs := r.Detect(g)
+		if !exploitStateFor(g.exploits, r).check(r, g.frame, ok) {
+			continue
+		}
+		g.s.respondExploit(r, "Guard "+g.color.String(), []*Guard{g}, objs)
+		if r.Response.Has(RespondReset) {
+			return
+		}
+	}
+}
+
+// exploitUpdate checks room anti-exploit rules and runs responses for the ones that fire.
+func (s *State) exploitUpdate() {
+	if s.exploits == nil {
+		s.exploits = make(map[string]*exploitState)
+	}
+	for i := range ExploitRules {
+		r := &ExploitRules[i]
+		if r.DetectRoom == nil {
+			continue
+		}
+		ok, objs := r.DetectRoom(s)
+		if !exploitStateFor(s.exploits, r).check(r, s.frame, ok) {
+			continue
+		}
+		s.respondExploit(r, "Room", s.bosses, objs)
+		if r.Response.Has(RespondReset) {
+			return
+		}
+	}
+}
+
+// respondExploit runs the response of the rule for given guards.
+func (s *State) respondExploit(r *ExploitRule, who string, guards []*Guard, objs ns4.Objects) {
+	if Debug {
+		fmt.Printf("%s: exploit %q detected (%d objects), response: %s\n", who, r.Name, len(objs), r.Response)
+	}
+	notify(EventExploit, 1)
+	for _, g := range guards {
+		if r.Response.Has(RespondInvulnerable) {
+			g.unit.Enchant(enchant.INVULNERABLE, ns4.Seconds(ExploitInvulnerableDur))
+		}
+		if r.Response.Has(RespondCounterspell) {
+			ns4.CastSpell(spell.COUNTERSPELL, g.unit, g.unit)
+		}
+		if r.Response.Has(RespondDispel) && len(objs) == 0 {
+			g.unit.EnchantOff(r.Enchant)
+		}
+		if r.Response.Has(RespondCleanup) && r.Cleanup != nil {
+			r.Cleanup(g, objs)
+		}
+	}
+	if r.Response.Has(RespondDispel) {
+		for _, obj := range objs {
+			obj.EnchantOff(r.Enchant)
+		}
+	}
+	if r.Response.Has(RespondCleanup) && r.Cleanup == nil {
+		objs.Delete()
+	}
+	if r.Response.Has(RespondReset) {
+		// Reset is delayed until all guards are updated.
+		s.exploitReset = true
+	}
+}
+
+// isPlayerOwned checks if the object is owned by any player.
+func isPlayerOwned(obj ns4.Obj) bool {
+	for _, pl := range ns4.Players() {
+		if u := pl.Unit(); u != nil && obj.HasOwner(u) {
+			return true
+		}
+	}
+	return false
+}
+
+// findPlayerObjects finds player-owned objects of given types around the guard.
+func (g *Guard) findPlayerObjects(r float64, types ...string) ns4.Objects {
+	var out ns4.Objects
+	ns4.FindObjects(func(obj ns4.Obj) bool {
+		out = append(out, obj)
+		return true
+	},
+		ns4.InCirclef{Center: g.unit, R: r},
+		ns4.HasTypeName(types),
+		ns4.ObjCondFunc(isPlayerOwned),
+	)
+	return out
+}
+
+// detectEnchant returns a detector that checks if the guard has a given enchant.
+func detectEnchant(e enchant.Enchant) func(g *Guard) (bool, ns4.Objects) {
+	return func(g *Guard) (bool, ns4.Objects) {
+		return g.unit.HasEnchant(e), nil
+	}
+}
+
+// detectPlayerOwner checks if one of the players took control over the guard.
+func detectPlayerOwner(g *Guard) (bool, ns4.Objects) {
+	return isPlayerOwned(g.unit), nil
+}
+
+// detectDeathBall finds player-owned Force of Nature balls near the guard.
+// The guard's own balls, created by the Green ability, are not owned by players, so they are ignored.
+func detectDeathBall(g *Guard) (bool, ns4.Objects) {
+	balls := g.findPlayerObjects(ExploitDeathBallR, "DeathBall")
+	return len(balls) != 0, balls
+}
+
+// removeDeathBalls deletes the balls, or reflects them back to the caster if ExploitDeathBallReflect is set.
+func removeDeathBalls(g *Guard, balls ns4.Objects) {
+	if !ExploitDeathBallReflect {
+		balls.Delete()
+		return
+	}
+	for _, ball := range balls {
+		// The guard becomes the owner, so the ball will hit players instead.
+		ball.SetOwner(g.unit)
+		ball.ApplyForce(ball.Pos().Sub(g.unit.Pos()).Normalize().Mul(ExploitDeathBallForce))
+	}
+}
+
+// detectFlames checks if the guard is standing in player-owned flames.
+func detectFlames(g *Guard) (bool, ns4.Objects) {
+	// Moving guard will leave the flames anyway.
+	if g.unit.Pos() != g.prevPos {
+		return false, nil
+	}
+	flames := g.findPlayerObjects(BossFlamesR, "SmallFlame", "MediumFlame", "Flame", "LargeFlame")
+	return len(flames) >= BossFlamesCnt, flames
+}
+
+// detectConfusion checks if the guard is confused.
+// If confusion is used as a taunt, it is handled by the threat logic instead, but only if the caster is known for sure.
+func detectConfusion(g *Guard) (bool, ns4.Objects) {
+	if !g.unit.HasEnchant(enchant.CONFUSED) {
+		return false, nil
+	}
+	if TauntEnchant == enchant.CONFUSED && g.tauntCaster() != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// detectDisplacement checks if the guard was moved further than it can walk in a single frame.
+// Movement caused by the encounter itself, like the leash or the explosion knockback, is allowed.
+func detectDisplacement(g *Guard) (bool, ns4.Objects) {
+	if g.knockbackPending() {
+		return false, nil
+	}
+	return g.unit.Pos().Sub(g.prevPos).Len() > ExploitPushDist, nil
+}
+
+// knockbackPending checks if the guard can be moved by the encounter: it's leashed, or was hit by an explosion knockback.
+func (g *Guard) knockbackPending() bool {
+	if g.evading || g.outFrames != 0 {
+		return true
+	}
+	df := ns4.Frame() - g.s.explodedAt
+	return g.s.explodedAt != 0 && df >= 0 && df <= ExploitKnockbackGrace
+}
+
+// restorePos puts the guard back to the position before it was pulled or pushed.
+func restorePos(g *Guard, _ ns4.Objects) {
+	g.unit.SetPos(g.prevPos)
+}
+
+// detectHarpoon checks for player harpoons attached to the guard.
+func detectHarpoon(g *Guard) (bool, ns4.Objects) {
+	bolts := g.findPlayerObjects(ExploitHarpoonR, "HarpoonBolt")
+	return len(bolts) != 0, bolts
+}
+
+// detectInvisiblePlayers checks for invisible players in the room, since the guards cannot target them.
+func detectInvisiblePlayers(s *State) (bool, ns4.Objects) {
+	var out ns4.Objects
+	s.EachPlayerInRoom(func(u ns4.Obj) {
+		if u.HasEnchant(enchant.INVISIBLE) {
+			out = append(out, u)
+		}
+	})
+	return len(out) != 0, out
+}
@@ -0,0 +1,104 @@
+package stoneguard
+
+import "testing"
+
+func TestExploitStateCheck(t *testing.T) {
+	cases := []struct {
+		name     string
+		rule     ExploitRule
+		detected []bool // detection result on each frame
+		fired    []int  // frames on which the rule is expected to fire
+	}{
+		{
+			name:     "immediate",
+			rule:     ExploitRule{},
+			detected: []bool{false, true, true, false, true},
+			fired:    []int{1, 2, 4},
+		},
+		{
+			name:     "cooldown",
+			rule:     ExploitRule{Cooldown: 3},
+			detected: []bool{true, true, true, true, true, true, true},
+			fired:    []int{0, 3, 6},
+		},
+		{
+			name:     "cooldown after gap",
+			rule:     ExploitRule{Cooldown: 3},
+			detected: []bool{true, false, true, false, true},
+			fired:    []int{0, 4},
+		},
+		{
+			name:     "limit",
+			rule:     ExploitRule{Limit: 3},
+			detected: []bool{true, true, true, true, true},
+			fired:    []int{3, 4},
+		},
+		{
+			name:     "limit restarts",
+			rule:     ExploitRule{Limit: 2},
+			detected: []bool{true, true, false, true, true, true},
+			fired:    []int{5},
+		},
+		{
+			name:     "limit and cooldown",
+			rule:     ExploitRule{Limit: 2, Cooldown: 3},
+			detected: []bool{true, true, true, true, true, true, true, true},
+			fired:    []int{2, 5},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := make(map[string]*exploitState)
+			st := exploitStateFor(m, &c.rule)
+			var fired []int
+			for frame, ok := range c.detected {
+				if st.check(&c.rule, frame, ok) {
+					fired = append(fired, frame)
+				}
+			}
+			if len(fired) != len(c.fired) {
+				t.Fatalf("unexpected frames: %v, expected %v", fired, c.fired)
+			}
+			for i := range fired {
+				if fired[i] != c.fired[i] {
+					t.Fatalf("unexpected frames: %v, expected %v", fired, c.fired)
+				}
+			}
+			if st.count != len(c.fired) {
+				t.Fatalf("unexpected count: %d", st.count)
+			}
+		})
+	}
+}
+
+func TestExploitStateFor(t *testing.T) {
+	m := make(map[string]*exploitState)
+	r1, r2 := &ExploitRule{Name: "a"}, &ExploitRule{Name: "b"}
+	st := exploitStateFor(m, r1)
+	if st.since != -1 || st.fired != -1 {
+		t.Fatalf("unexpected initial state: %+v", *st)
+	}
+	if exploitStateFor(m, r1) != st {
+		t.Fatal("state is not reused")
+	}
+	if exploitStateFor(m, r2) == st {
+		t.Fatal("state is shared between rules")
+	}
+}
+
+func TestExploitResponseString(t *testing.T) {
+	cases := []struct {
+		r   ExploitResponse
+		exp string
+	}{
+		{0, ""},
+		{RespondCounterspell, "counterspell"},
+		{RespondCleanup | RespondInvulnerable, "invulnerable+cleanup"},
+		{RespondReset, "reset"},
+	}
+	for _, c := range cases {
+		if got := c.r.String(); got != c.exp {
+			t.Errorf("unexpected string for %d: %q, expected %q", int(c.r), got, c.exp)
+		}
+	}
+}
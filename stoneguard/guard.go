@@ -15,7 +15,8 @@ import (
 func (s *State) NewGuard(color Element, pos types.Pointf) *Guard {
 	g := &Guard{s: s, color: color}
 	// Create an actual boss unit and set it up.
-	g.unit = ns4.CreateObject(BossModel, pos)
+	g.unit = s.objs.Create(g.owner(), BossModel, pos)
+	g.spawn = pos
 	g.prevPos = pos
 	g.unit.LookWithAngle(32)
 	// We set the health to the value of the common health pool.
@@ -44,6 +45,15 @@ func (s *State) NewGuard(color Element, pos types.Pointf) *Guard {
 	return g
 }
 
+// castBarStyle is a style of the cast bar shown above the guard health bar.
+var castBarStyle = ui.BarStyle{
+	Orientation: ui.Horizontal,
+	Length:      56,
+	Slots:       8,
+	Offset:      ns4.Pointf{X: 0, Y: -62},
+	Models:      []string{"DrainManaOrb"},
+}
+
 // Guard contains state for one Stone Guard boss unit.
 type Guard struct {
 	s       *State
@@ -51,15 +61,24 @@ type Guard struct {
 	unit    ns4.Obj
 	hp      *ui.HealthBar
 	ep      *ui.EnergyBar
+	cast    *ui.CastBar
 	prevHP  int
 	prevPos ns4.Pointf
+	spawn   ns4.Pointf
 	frame   int
 
-	hitByDeathBall bool
-
 	energy     int
 	forceField ns4.Obj
 
+	exploits map[string]*exploitState
+
+	threat   threatTable
+	steering bool
+
+	evading    bool
+	evadeFrame int
+	outFrames  int // how long the guard stays out of the room
+
 	abil Ability
 }
 
@@ -67,16 +86,20 @@ type Guard struct {
 func (g *Guard) Delete() {
 	// delete health and energy meters
 	if g.hp != nil {
-		g.hp.Delete()
+		g.s.objs.DeleteSource(g.hp)
 		g.hp = nil
 	}
 	if g.ep != nil {
-		g.ep.Delete()
+		g.s.objs.DeleteSource(g.ep)
 		g.ep = nil
 	}
+	if g.cast != nil {
+		g.s.objs.DeleteSource(g.cast)
+		g.cast = nil
+	}
 	// delete force field
 	if g.forceField != nil {
-		g.forceField.Delete()
+		g.s.objs.Delete(g.forceField)
 		g.forceField = nil
 	}
 	// delete ability
@@ -85,18 +108,43 @@ func (g *Guard) Delete() {
 		g.abil = nil
 	}
 	// finally, delete the actual unit
-	g.unit.Delete()
+	g.s.objs.Delete(g.unit)
+}
+
+// owner returns the name used to track objects created by the guard.
+func (g *Guard) owner() string {
+	return g.color.String() + " guard"
 }
 
 // Start unfreezes the boss and makes it start fighting.
 func (g *Guard) Start() {
 	g.hp = ui.NewHealthBar(g.unit)
 	g.ep = ui.NewEnergyBar(g.unit)
+	g.cast = ui.NewCastBar(g.unit, castBarStyle)
+	g.s.objs.TrackSource(g.owner(), g.hp)
+	g.s.objs.TrackSource(g.owner(), g.ep)
+	g.s.objs.TrackSource(g.owner(), g.cast)
 	g.unit.Freeze(false)
 	g.unit.EnchantOff(enchant.FREEZE)
 	ns4.CastSpell(spell.COUNTERSPELL, g.unit, g.unit)
 }
 
+// startCast shows the cast bar for the ability with a given name, charging for dur seconds.
+func (g *Guard) startCast(name string, dur int) {
+	if g.cast == nil {
+		return
+	}
+	g.cast.Start(name, dur*ns4.FrameRate())
+}
+
+// stopCast hides the cast bar, when the charging ability was cancelled.
+func (g *Guard) stopCast() {
+	if g.cast == nil {
+		return
+	}
+	g.cast.Stop()
+}
+
 // HealthDelta calculates the heal/damage delta for the current frame.
 func (g *Guard) HealthDelta() int {
 	return g.unit.CurrentHealth() - g.prevHP
@@ -111,13 +159,29 @@ func (g *Guard) Update() {
 	if g.ep != nil {
 		g.ep.Update()
 	}
-	// prevent certain spells that are abusive
-	g.antiSpell()
-	// run the force field and energy logic
-	g.gatherEnergyOrShield()
-	// run the unique ability for the unit (if any)
-	if g.abil != nil {
-		g.abil.Update(g)
+	if g.s.frozen {
+		// the guard is frozen by a debug command - only keep the bars up to date
+		g.prevPos = g.unit.Pos()
+		g.prevHP = g.unit.CurrentHealth()
+		return
+	}
+	if g.cast != nil {
+		g.cast.Update()
+	}
+	// return to spawn if the guard left the room, otherwise run the fight logic
+	if !g.leashUpdate() {
+		// prevent certain spells and tricks that are abusive
+		g.exploitUpdate()
+		// select the target based on threat
+		g.threatUpdate(g.HealthDelta())
+		// move the guard according to the steering behaviours
+		g.steerUpdate()
+		// run the force field and energy logic
+		g.gatherEnergyOrShield()
+		// run the unique ability for the unit (if any)
+		if g.abil != nil {
+			g.abil.Update(g)
+		}
 	}
 	// some bookkeeping
 	g.prevPos = g.unit.Pos()
@@ -125,90 +189,6 @@ func (g *Guard) Update() {
 	g.frame++
 }
 
-// antiSpell prevents certain abusive spells from affecting the boss unit.
-func (g *Guard) antiSpell() {
-	g.removeFlames()
-	g.removeDeathBall()
-	g.stopCharming()
-}
-
-// removeFlames removes the flames under the boss if it's standing in them for too long.
-func (g *Guard) removeFlames() {
-	// If boss is standing on the same spot as the last frame.
-	if g.unit.Pos() == g.prevPos {
-		// Count the player-owned flames under it.
-		flames := ns4.FindObjects(nil,
-			// Check in certain radius, usually corresponding to the unit model size.
-			ns4.InCirclef{Center: g.unit, R: BossFlamesR},
-			// We are only interested in flames.
-			ns4.HasTypeName{
-				"SmallFlame",
-				"MediumFlame",
-				"Flame",
-				"LargeFlame",
-			},
-			// Only consider player-owned flames, since boss itself may use flame for the unique abilities.
-			ns4.ObjCondFunc(func(obj ns4.Obj) bool {
-				playerOwn := false
-				for _, pl := range ns4.Players() {
-					u := pl.Unit()
-					if obj.HasOwner(u) {
-						playerOwn = true
-					}
-				}
-				return playerOwn
-			},
-			))
-		// If there are too many flames under it - trigger a breaking water barrel to put them out.
-		if flames >= BossFlamesCnt {
-			barrel := ns4.CreateObject("WaterBarrel", g.unit.Pos())
-			barrel.Damage(g.unit, 100, 1)
-		}
-	}
-}
-
-// removeDeathBall removes a Death Ball (Force of Nature projectile) from the boss if it damage it too much.
-func (g *Guard) removeDeathBall() {
-	dHP := -g.HealthDelta()
-	// If damage crosses a threshold for 2 frames in a row - counter-spell the ball and make unit temporarily invulnerable.
-	if dHP >= 10 {
-		if g.hitByDeathBall {
-			g.unit.Enchant(enchant.INVULNERABLE, ns4.Seconds(1))
-			ns4.CastSpell(spell.COUNTERSPELL, g.unit, g.unit)
-		} else {
-			g.hitByDeathBall = true
-		}
-	} else {
-		g.hitByDeathBall = false
-	}
-	// TODO: fix DeathBall search in NS4
-
-	// deaths := 0
-	// ns4.FindObjects(
-	// 	func(it ns4.Obj) bool {
-	// 		deaths++
-	// 		if !g.hitByDeathBall {
-	// 			g.hitByDeathBall = true
-	// 			return false
-	// 		}
-	// 		it.Delete()
-	// 		return true
-	// 	},
-	// 	ns4.InCirclef{Center: g.obj, R: 100},
-	// 	ns4.HasTypeName{"DeathBall"},
-	// )
-	// if deaths == 0 {
-	// 	g.hitByDeathBall = false
-	// }
-}
-
-// stopCharming stops charming attempts toward the boss.
-func (g *Guard) stopCharming() {
-	if g.unit.HasEnchant(enchant.CHARMING) {
-		ns4.CastSpell(spell.COUNTERSPELL, g.unit, g.unit)
-	}
-}
-
 // gatherEnergyOrShield is responsible for boss energy logic and the force field.
 func (g *Guard) gatherEnergyOrShield() {
 	if g.frame < EnergyDelay*ns4.FrameRate() {
@@ -227,13 +207,16 @@ func (g *Guard) gatherEnergyOrShield() {
 	if hasAnother {
 		// Gather energy while at least one another boss is around.
 		if g.forceField != nil {
-			g.forceField.Delete()
+			g.s.objs.Delete(g.forceField)
 			g.forceField = nil
 		}
 		// Energy is increased each second.
 		if g.frame%ns4.FrameRate() == 0 {
 			if df := ns4.Frame() - g.s.explodedAt; df <= 0 || df > EnergyDelay*ns4.FrameRate() {
 				g.energy++
+				if g.energy == EnergyExplosionChargeDur-AnnounceExplosionSoon {
+					g.s.Announce(PriorityNormal, msgExplosionSoon, g.color.GuardName())
+				}
 			}
 		}
 		// When charged to 100% - trigger explosion.
@@ -241,13 +224,18 @@ func (g *Guard) gatherEnergyOrShield() {
 			g.triggerExplosion()
 			g.energy = 0
 		}
-		// Update energy bar on the unit.
+		// Update energy bar on the unit. It flashes when the explosion is near.
 		g.ep.Set(float32(g.energy) / float32(EnergyExplosionChargeDur))
+		if g.energy >= EnergyExplosionChargeDur-EnergyFlashBefore && (g.frame/EnergyFlashInterval)%2 == 0 {
+			g.ep.Hide()
+		} else {
+			g.ep.Show()
+		}
 	} else {
 		// If no other boss is around - make unit invulnerable and show a force field.
 		g.unit.Enchant(enchant.INVULNERABLE, ns4.Frames(2))
 		if g.forceField == nil {
-			g.forceField = ns4.CreateObject(EnergyShieldModel, g.unit)
+			g.forceField = g.s.objs.Create(g.owner(), EnergyShieldModel, g.unit)
 		}
 		g.forceField.SetPos(g.unit.Pos())
 	}
@@ -256,12 +244,15 @@ func (g *Guard) gatherEnergyOrShield() {
 // triggerExplosion creates an elemental explosion from the unit.
 func (g *Guard) triggerExplosion() {
 	g.s.explodedAt = ns4.Frame()
+	notify(EventExplosion, 1)
+	g.s.Announce(PriorityHigh, msgExplosion, g.color.GuardName())
 	ns4.CastSpell(spell.TURN_UNDEAD, g.unit, g.unit)
 	var dmg int
 	if g.color == g.s.curEffect {
 		// If room effect matches the unit color/element - deal minor damage and switch room effect.
 		dmg = EnergyExplosionDamageWeak
 		fmt.Println("Guard triggered effect switch!")
+		g.s.clearPetrifyElement(g.color)
 		g.s.nextRoomEffect()
 	} else {
 		// If room effect doesn't match the unit color/element - deal major damage and keep the effect.
@@ -273,4 +264,9 @@ func (g *Guard) triggerExplosion() {
 	g.s.EachPlayerInRoom(func(u ns4.Obj) {
 		u.Damage(nil, dmg, typ)
 	})
+	if CreaturesExplosionDamage {
+		g.s.EachCreatureInRoom(func(u ns4.Obj) {
+			u.Damage(nil, dmg, typ)
+		})
+	}
 }
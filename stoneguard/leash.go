@@ -0,0 +1,103 @@
+package stoneguard
+
+// This file contains leashing logic for the guards.
+// It prevents players from kiting guards out of the room, where room effects and abilities do not reach.
+
+import (
+	"fmt"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+	"github.com/noxworld-dev/noxscript/ns/v4/enchant"
+)
+
+// IsEvading checks if the guard is returning to its spawn point after leaving the room.
+func (g *Guard) IsEvading() bool {
+	return g.evading
+}
+
+// leashUpdate checks if the guard left the room and runs the evade logic.
+// It returns true if the guard is evading and should skip the rest of the fight logic.
+func (g *Guard) leashUpdate() bool {
+	inRoom := inRoomArea(g.unit.Pos(), -LeashTolerance)
+	if inRoom {
+		g.outFrames = 0
+	} else {
+		g.outFrames++
+	}
+	if !g.evading {
+		if inRoom {
+			return false
+		}
+		g.startEvade()
+	}
+	g.evadeFrame++
+	if g.unit.Pos().Sub(g.spawn).Len() < SteerHomeDist || g.evadeFrame > LeashEvadeTimeout*ns4.FrameRate() {
+		g.stopEvade()
+		return false
+	}
+	g.unit.Enchant(enchant.INVULNERABLE, ns4.Frames(2))
+	if g.evadeFrame%SteerInterval == 0 {
+		g.unit.WalkTo(g.spawn)
+	}
+	return true
+}
+
+// startEvade makes the guard drop all targets, return to spawn and heal the shared pool.
+// The pool is healed only once, even if several guards are kited out of the room together.
+func (g *Guard) startEvade() {
+	if Debug {
+		fmt.Printf("Guard %s left the room, evading!\n", g.color.String())
+	}
+	heal := !g.s.anyEvading()
+	g.evading = true
+	g.evadeFrame = 0
+	g.threat.Reset()
+	g.steering = false
+	g.unit.AggressionLevel(0)
+	g.unit.WalkTo(g.spawn)
+	if !heal {
+		return
+	}
+	// Heal the shared health pool, so that kiting is not rewarded.
+	g.s.health += BossHealth * LeashHealPerc / 100
+	if g.s.health > BossHealth {
+		g.s.health = BossHealth
+	}
+}
+
+// stopEvade returns the guard to the fight.
+func (g *Guard) stopEvade() {
+	g.evading = false
+	// Put the guard back in case it got stuck on the way.
+	g.unit.SetPos(g.spawn)
+	g.prevPos = g.spawn
+	g.unit.EnchantOff(enchant.INVULNERABLE)
+	if !Debug {
+		g.unit.AggressionLevel(BossAggression)
+	}
+}
+
+// anyEvading checks if at least one guard is evading.
+func (s *State) anyEvading() bool {
+	for _, g := range s.bosses {
+		if g.evading {
+			return true
+		}
+	}
+	return false
+}
+
+// leashBroken checks if the encounter must be reset, because the guards cannot be returned to the room.
+// It happens when all the guards left the room, or when any of them stays out longer than LeashOutLimit.
+func (s *State) leashBroken() bool {
+	all := len(s.bosses) != 0
+	for _, g := range s.bosses {
+		if g.outFrames > LeashOutLimit*ns4.FrameRate() {
+			return true
+		}
+		if !g.evading {
+			all = false
+		}
+	}
+	return all
+}
@@ -3,19 +3,86 @@ package stoneguard
 import (
 	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
 	"github.com/noxworld-dev/opennox-lib/object"
+
+	"mogushan/ui"
 )
 
 // state contains all state of the boss zone
 var state State
 
+// registered is set once the handlers are registered with the script runtime.
+var registered bool
+
 func init() {
+	Register()
+}
+
+// Register registers all map events and chat commands of the boss zone.
+// It is called automatically when the map script is loaded.
+// Tools that run the encounter outside the game must call it again after setting the script runtime.
+// Handlers are registered only once, and only when the runtime is set.
+func Register() {
+	if registered || ns4.Runtime() == nil {
+		return
+	}
+	registered = true
+	// register demo scene events
+	ns4.OnMapEvent(ns4.MapInitialize, demoState.Reset)
+	ns4.OnFrame(demoState.Update)
 	// register map events
 	ns4.OnMapEvent(ns4.MapInitialize, state.Reset)
 	ns4.OnFrame(state.Update)
+	// register debug chat commands
+	ns4.OnChat(state.onCommand)
+	// register the ready-check chat command
+	ns4.OnChat(state.onPullChat)
+	// register the opt-out chat command
+	ns4.OnChat(state.onOptOutChat)
+}
+
+// Event is an encounter event reported to the Observer.
+type Event int
+
+const (
+	EventPull          = Event(iota) // fight started
+	EventKill                        // fight ended with the boss death
+	EventWipe                        // fight ended with the raid death
+	EventEvade                       // fight reset because the guards left the room
+	EventEffectTimeout               // room effect switched on timeout
+	EventExplosion                   // guard energy explosion
+	EventExploit                     // anti-exploit rule fired
+	EventLeak                        // objects were not deleted by their owners before Reset
+)
+
+// Observer is notified about encounter events. It is installed by tools such as the encounter simulator.
+type Observer interface {
+	// EncounterEvent is called for each event. The n is the number of objects for EventLeak, and 1 otherwise.
+	EncounterEvent(e Event, n int)
+}
+
+// observer receives encounter events, if set.
+var observer Observer
+
+// SetObserver installs an observer for encounter events. Nil removes it.
+func SetObserver(o Observer) {
+	observer = o
+}
+
+// notify reports an encounter event to the observer.
+func notify(e Event, n int) {
+	if observer != nil {
+		observer.EncounterEvent(e, n)
+	}
+}
+
+// FightState returns current state of the boss.
+func FightState() BossState {
+	return state.state
 }
 
 const (
 	BossWaiting = iota
+	BossCountdown
 	BossFighting
 	BossDead
 )
@@ -33,6 +100,25 @@ type State struct {
 	roomEffectStart int
 	explodedAt      int
 	bosses          []*Guard
+	wipePending     []ns4.Player    // dead participants that will be moved to the graveyard after respawn
+	pullWarnAt      int             // last frame when a player was warned about getting too close
+	pullTimers      []*ui.Countdown // pull countdown shown above each guard
+	lever           ns4.Obj         // lever that starts the ready-check
+	leverOn         bool            // last known state of the lever
+	petrify         []*petrifyMeter
+	telegraphs      []*Telegraph
+	ann             announcer
+	raid            *raidFrame
+	markers         []*debuffMarker
+	frozen          bool // bosses are frozen by a debug command
+	overlay         *debugOverlay
+	exploitReset    bool                     // an anti-exploit rule requested the encounter reset
+	exploits        map[string]*exploitState // state of the room anti-exploit rules
+	roster          []*rosterEntry           // participants locked at pull time
+	optOut          map[string]bool          // names of players that do not want to take part in the fight
+	manaPrev        map[ns4.Obj]int          // mana of players in the room on the previous frame
+	manaSpentAt     map[ns4.Obj]int          // frame when the player spent enough mana to cast TauntSpell
+	objs            objectRegistry           // engine objects created by the encounter
 }
 
 // IsAlive checks if boss is still alive.
@@ -50,12 +136,29 @@ func (s *State) Delete() {
 	for _, g := range s.bosses {
 		g.Delete()
 	}
+	s.deleteTelegraphs()
+	s.deleteMarkers()
+	s.stopCountdown()
+	s.deleteLever()
+	if s.raid != nil {
+		s.raid.Delete()
+		s.raid = nil
+	}
+	// the overlay is redrawn on the next frame, if enabled
+	if s.overlay != nil {
+		s.overlay.Delete()
+		s.overlay = nil
+	}
+	// remove anything that was missed above
+	s.objs.Cleanup("Stone Guard")
 }
 
 // Reset the boss to the starting state.
 func (s *State) Reset() {
 	// delete old boss
 	s.Delete()
+	// restore players affected by the fight
+	s.clearPetrify()
 	// open entrance
 	s.switchEntrance(true)
 	// respawn the boss
@@ -69,12 +172,20 @@ func (s *State) spawnBoss() {
 	s.firstEffect = true
 	s.frame = 0
 	s.state = BossWaiting
+	s.frozen = false
+	s.exploitReset = false
+	s.exploits = nil
+	s.roster = nil
+	s.wipePending = nil
+	s.manaPrev = nil
+	s.manaSpentAt = nil
 	s.bosses = nil
 	// spawn the bosses
 	spawns := s.randomBossPos()
 	for i := 0; i < 3; i++ {
 		s.NewGuard(Element(i)%colorMax, spawns[i])
 	}
+	s.spawnLever()
 }
 
 // EachPlayerInRoom is a helper that iterates over all alive players in the boss room.
@@ -99,9 +210,13 @@ func (s *State) ArePlayersAlive() bool {
 
 // Update the boss state. This is the main script function.
 func (s *State) Update() {
+	s.wipePendingUpdate()
+	s.overlayUpdate()
 	switch s.state {
 	case BossWaiting:
 		s.waitingUpdate()
+	case BossCountdown:
+		s.countdownUpdate()
 	case BossFighting:
 		s.fightingUpdate()
 	case BossDead:
@@ -112,42 +227,74 @@ func (s *State) Update() {
 // waitingUpdate is the update function for the BossWaiting state.
 func (s *State) waitingUpdate() {
 	// check if player attempts to charm the boss
+	if s.isCharmed() {
+		// reset the fight immediately
+		s.Reset()
+		return
+	}
+	// check if the ready-check lever was used
+	s.leverUpdate()
+	if s.state != BossWaiting {
+		return
+	}
+	// check if players are close enough to start a fight
+	if pl := s.playerTooClose(); pl != nil {
+		if PullReadyCheck {
+			// only warn the player, the fight must be started with a ready-check
+			s.pullWarning(pl)
+			return
+		}
+		s.startFight()
+	}
+}
+
+// isCharmed checks if any player attempts to charm the boss.
+func (s *State) isCharmed() bool {
 	for _, pl := range ns4.Players() {
 		u := pl.Unit()
 		for _, g := range s.bosses {
 			if g.unit.HasOwner(u) {
-				// reset the fight immediately
-				s.Reset()
-				return
+				return true
 			}
 		}
 	}
-	// check if players are close enough to start a fight
-	tooClose := false
+	return false
+}
+
+// playerTooClose finds an alive player that is close enough to any boss unit to start a fight.
+func (s *State) playerTooClose() ns4.Obj {
 	for _, g := range s.bosses {
 		pl := ns4.FindClosestObject(g.unit, ns4.HasClass(object.ClassPlayer), ns4.ObjCondFunc(func(obj ns4.Obj) bool {
 			return obj.CurrentHealth() > 0
 		}))
 		if pl != nil && g.unit.Pos().Sub(pl.Pos()).Len() < BossStartFightDist {
-			tooClose = true
-			break
+			return pl
 		}
 	}
-	if tooClose {
-		s.startFight()
-	}
+	return nil
 }
 
 // startFight starts the boss fight. It switches boss state to BossFighting.
 func (s *State) startFight() {
+	notify(EventPull, 1)
+	// the countdown and the lever are not needed anymore
+	s.stopCountdown()
+	s.deleteLever()
 	// set shared boss health pool
 	s.health = BossHealth
-	// teleport players that are not in the room already
+	// lock the participants and teleport the ones that are not in the room already
+	s.lockRoster()
 	s.teleportPlayersToRoom()
+	// remove player creatures, if not allowed
+	if CreaturesDispelOnPull {
+		s.dispelCreatures()
+	}
 	// start the bosses
 	for _, g := range s.bosses {
 		g.Start()
 	}
+	// show the raid frame
+	s.raid = s.newRaidFrame()
 	// close the entrance
 	s.switchEntrance(false)
 	// init other state
@@ -159,18 +306,21 @@ func (s *State) startFight() {
 
 // bossDead ends the boss fight with boss death. Switches state to BossDead.
 func (s *State) bossDead() {
+	notify(EventKill, 1)
 	s.state = BossDead
 	// delete all remaining state
-	for _, g := range s.bosses {
-		g.Delete()
-	}
+	s.Delete()
+	// restore players affected by the fight
+	s.clearPetrify()
 	// TODO: prize!
 }
 
 // fightingUpdate is the update function for the BossFighting state.
 func (s *State) fightingUpdate() {
+	s.rosterUpdate()
+	s.manaUpdate(true)
 	if !s.ArePlayersAlive() {
-		s.Reset()
+		s.wipe()
 		return
 	}
 	if !s.IsAlive() {
@@ -188,10 +338,39 @@ func (s *State) fightingUpdate() {
 	for _, g := range s.bosses {
 		g.Update()
 	}
+	if !s.frozen {
+		s.exploitUpdate()
+	}
+	if s.exploitReset {
+		// one of the anti-exploit rules caught an abuse that cannot be countered
+		s.Reset()
+		return
+	}
+	if s.leashBroken() {
+		// the guards were pulled out of the room - reset the encounter
+		notify(EventEvade, 1)
+		s.Reset()
+		return
+	}
 	for _, g := range s.bosses {
 		g.unit.SetHealth(s.health)
 		g.prevHP = s.health
 	}
+	if s.frozen {
+		// Everything is paused by the debug command, including the fight timers.
+		if s.raid != nil {
+			s.raid.Update(s)
+		}
+		return
+	}
+	s.creatureCapUpdate()
+	s.telegraphUpdate()
+	s.markersUpdate()
 	s.roomEffectUpdate()
+	// mana drained by the room effect must not be mistaken for a spell cast
+	s.manaUpdate(false)
+	if s.raid != nil {
+		s.raid.Update(s)
+	}
 	s.frame++
 }
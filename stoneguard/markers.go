@@ -0,0 +1,108 @@
+package stoneguard
+
+// This file contains overhead debuff markers on player units, visible to the whole raid.
+
+import (
+	"math"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+// Debuff is a kind of debuff marker shown on players.
+type Debuff int
+
+const (
+	DebuffRedTarget = Debuff(iota)
+	DebuffStunned
+)
+
+// Model returns an object model for the debuff marker.
+func (d Debuff) Model() string {
+	switch d {
+	case DebuffRedTarget:
+		return MarkerRedTargetModel
+	case DebuffStunned:
+		return MarkerStunnedModel
+	}
+	return MarkerStunnedModel
+}
+
+// debuffMarker is a single marker orbiting the player.
+type debuffMarker struct {
+	unit  ns4.Obj
+	kind  Debuff
+	obj   ns4.Obj
+	left  int // frames left, or -1 if the marker stays until detached
+	frame int
+}
+
+// AttachMarker shows a debuff marker over the player for dur seconds.
+// If dur is zero, the marker stays until DetachMarker is called.
+// If the marker already exists, its duration is refreshed.
+func (s *State) AttachMarker(u ns4.Obj, kind Debuff, dur float64) {
+	left := -1
+	if dur > 0 {
+		left = int(dur * float64(ns4.FrameRate()))
+	}
+	for _, m := range s.markers {
+		if m.unit == u && m.kind == kind {
+			m.left = left
+			return
+		}
+	}
+	s.markers = append(s.markers, &debuffMarker{
+		unit: u,
+		kind: kind,
+		obj:  s.objs.Create("markers", kind.Model(), u),
+		left: left,
+	})
+}
+
+// DetachMarker removes a debuff marker from the player.
+func (s *State) DetachMarker(u ns4.Obj, kind Debuff) {
+	for _, m := range s.markers {
+		if m.unit == u && m.kind == kind {
+			m.left = 0
+		}
+	}
+}
+
+// markersUpdate moves markers around players and removes expired ones.
+func (s *State) markersUpdate() {
+	for i := 0; i < len(s.markers); i++ {
+		m := s.markers[i]
+		if m.left > 0 {
+			m.left--
+		}
+		if m.left == 0 || m.unit.CurrentHealth() <= 0 {
+			s.objs.Delete(m.obj)
+			s.markers = append(s.markers[:i], s.markers[i+1:]...)
+			i--
+		}
+	}
+	// Markers on the same player are spread evenly around the orbit.
+	for _, m := range s.markers {
+		m.frame++
+		idx, cnt := 0, 0
+		for _, m2 := range s.markers {
+			if m2.unit != m.unit {
+				continue
+			}
+			if m2 == m {
+				idx = cnt
+			}
+			cnt++
+		}
+		ph := float64(idx)*2*math.Pi/float64(cnt) + float64(m.frame)*MarkerSpeed
+		pos := m.unit.Pos().Add(ns4.Ptf(MarkerR*float32(math.Cos(ph)), MarkerR*float32(math.Sin(ph))-MarkerHeight))
+		m.obj.SetPos(pos)
+	}
+}
+
+// deleteMarkers removes all debuff markers.
+func (s *State) deleteMarkers() {
+	for _, m := range s.markers {
+		s.objs.Delete(m.obj)
+	}
+	s.markers = nil
+}
@@ -0,0 +1,142 @@
+package stoneguard
+
+// This file contains the object registry, which tracks all engine objects created by the encounter.
+// Subsystems delete their objects through the registry, and anything they miss is removed on Reset and reported as a leak.
+
+import (
+	"fmt"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+	"github.com/noxworld-dev/opennox-lib/object"
+)
+
+// objectSource is anything that creates and deletes its own engine objects, for example UI widgets.
+type objectSource interface {
+	Objects() ns4.Objects
+	Delete()
+}
+
+// objectRegistry tracks engine objects, grouped by the owner name.
+//
+// Objects should be removed from it with Delete, or with Forget if the engine destroyed them on its own.
+// Objects the engine destroyed without a Forget, like broken flames or killed summons, are skipped on Cleanup.
+type objectRegistry struct {
+	owners  []string // owners in order of registration, for a stable report
+	objs    map[ns4.Obj]string
+	sources map[objectSource]string
+}
+
+// addOwner registers the owner, if it's not registered yet.
+func (r *objectRegistry) addOwner(owner string) {
+	if r.objs == nil {
+		r.objs = make(map[ns4.Obj]string)
+		r.sources = make(map[objectSource]string)
+	}
+	for _, name := range r.owners {
+		if name == owner {
+			return
+		}
+	}
+	r.owners = append(r.owners, owner)
+}
+
+// Create a new object and track it for a given owner.
+func (r *objectRegistry) Create(owner, model string, pos ns4.Positioner) ns4.Obj {
+	obj := ns4.CreateObject(model, pos)
+	if obj == nil {
+		return nil
+	}
+	r.addOwner(owner)
+	r.objs[obj] = owner
+	return obj
+}
+
+// TrackSource tracks all objects of the source for a given owner.
+func (r *objectRegistry) TrackSource(owner string, src objectSource) {
+	r.addOwner(owner)
+	r.sources[src] = owner
+}
+
+// Delete objects and stop tracking them. Nil objects are ignored.
+func (r *objectRegistry) Delete(objs ...ns4.Obj) {
+	for _, obj := range objs {
+		if obj == nil {
+			continue
+		}
+		delete(r.objs, obj)
+		obj.Delete()
+	}
+}
+
+// DeleteSource deletes all objects of the source and stops tracking it.
+func (r *objectRegistry) DeleteSource(src objectSource) {
+	delete(r.sources, src)
+	src.Delete()
+}
+
+// Forget stops tracking objects that were destroyed by the engine, for example broken or exploded ones.
+func (r *objectRegistry) Forget(objs ...ns4.Obj) {
+	for _, obj := range objs {
+		delete(r.objs, obj)
+	}
+}
+
+// isGone checks if the object was already destroyed or killed by the engine.
+func isGone(obj ns4.Obj) bool {
+	return obj.Flags().HasAny(object.FlagDestroyed | object.FlagDead)
+}
+
+// live returns all tracked objects of the owner that were not destroyed by the engine.
+func (r *objectRegistry) live(owner string) ns4.Objects {
+	var out ns4.Objects
+	for obj, name := range r.objs {
+		if name == owner && !isGone(obj) {
+			out = append(out, obj)
+		}
+	}
+	for src, name := range r.sources {
+		if name == owner {
+			out = append(out, src.Objects()...)
+		}
+	}
+	return out
+}
+
+// Report returns a number of live objects for each owner that has any.
+func (r *objectRegistry) Report() []string {
+	var lines []string
+	for _, owner := range r.owners {
+		if n := len(r.live(owner)); n != 0 {
+			lines = append(lines, fmt.Sprintf("%s: %d objects", owner, n))
+		}
+	}
+	return lines
+}
+
+// Cleanup deletes all objects that are still tracked and forgets all owners.
+// It should be called after the regular cleanup, so any object deleted here is a leak.
+// Objects that were already destroyed by the engine are not leaks, so they are only forgotten.
+// Leaks are reported to the observer, and printed in Debug mode.
+func (r *objectRegistry) Cleanup(name string) {
+	for _, owner := range r.owners {
+		live := r.live(owner)
+		if len(live) == 0 {
+			continue
+		}
+		if Debug {
+			fmt.Printf("%s: %s leaked %d objects\n", name, owner, len(live))
+		}
+		notify(EventLeak, len(live))
+	}
+	for obj := range r.objs {
+		if !isGone(obj) {
+			obj.Delete()
+		}
+	}
+	for src := range r.sources {
+		src.Delete()
+	}
+	r.owners = nil
+	r.objs = nil
+	r.sources = nil
+}
@@ -0,0 +1,75 @@
+package stoneguard
+
+import (
+	"testing"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+	"github.com/noxworld-dev/opennox-lib/object"
+)
+
+// flagObj is a fake object with flags that records deletion.
+type flagObj struct {
+	ns4.Obj
+	flags   object.Flags
+	deleted bool
+}
+
+func (o *flagObj) Flags() object.Flags {
+	return o.flags
+}
+
+func (o *flagObj) Delete() {
+	o.deleted = true
+}
+
+// leakCounter is an Observer that counts leaked objects.
+type leakCounter int
+
+func (c *leakCounter) EncounterEvent(e Event, n int) {
+	if e == EventLeak {
+		*c += leakCounter(n)
+	}
+}
+
+func TestObjectRegistryCleanup(t *testing.T) {
+	var (
+		live      = &flagObj{}
+		destroyed = &flagObj{flags: object.FlagDestroyed}
+		dead      = &flagObj{flags: object.FlagDead}
+		forgotten = &flagObj{}
+		deleted   = &flagObj{}
+	)
+	var r objectRegistry
+	r.addOwner("a")
+	r.addOwner("b")
+	for _, obj := range []*flagObj{live, destroyed, forgotten, deleted} {
+		r.objs[obj] = "a"
+	}
+	r.objs[dead] = "b"
+	r.Forget(forgotten)
+	r.Delete(deleted, nil)
+	if !deleted.deleted {
+		t.Fatal("object was not deleted")
+	}
+
+	if rep := r.Report(); len(rep) != 1 || rep[0] != "a: 1 objects" {
+		t.Fatalf("unexpected report: %q", rep)
+	}
+
+	var leaks leakCounter
+	SetObserver(&leaks)
+	defer SetObserver(nil)
+	r.Cleanup("test")
+	if leaks != 1 {
+		t.Fatalf("unexpected leaks: %d", leaks)
+	}
+	if !live.deleted {
+		t.Fatal("leaked object was not deleted")
+	}
+	if destroyed.deleted || dead.deleted || forgotten.deleted {
+		t.Fatal("object destroyed by the engine was deleted again")
+	}
+	if len(r.owners) != 0 || len(r.objs) != 0 {
+		t.Fatal("registry was not cleared")
+	}
+}
@@ -0,0 +1,146 @@
+package stoneguard
+
+// This file contains the debug overlay that visualizes hidden radii and zones in-game.
+
+import (
+	"math"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+// markerPool is a pool of marker objects of the same model that are reused between redraws.
+type markerPool struct {
+	reg   *objectRegistry
+	model string
+	objs  ns4.Objects
+	used  int
+}
+
+// Add shows one more marker at a given position.
+func (p *markerPool) Add(pos ns4.Pointf) {
+	if p.used >= len(p.objs) {
+		p.objs = append(p.objs, p.reg.Create("overlay", p.model, pos))
+	}
+	o := p.objs[p.used]
+	o.SetPos(pos)
+	o.Enable(true)
+	p.used++
+}
+
+// Flush hides all markers that were not used during this redraw.
+func (p *markerPool) Flush() {
+	for _, o := range p.objs[p.used:] {
+		o.Enable(false)
+	}
+	p.used = 0
+}
+
+// Delete all marker objects.
+func (p *markerPool) Delete() {
+	p.reg.Delete(p.objs...)
+	p.objs = nil
+	p.used = 0
+}
+
+// debugOverlay draws hidden distances of the encounter with marker objects.
+type debugOverlay struct {
+	frame     int
+	guards    markerPool // radii around the guards
+	abilities markerPool // radii of active spells
+	room      markerPool // room boundaries
+}
+
+func newDebugOverlay(reg *objectRegistry) *debugOverlay {
+	return &debugOverlay{
+		guards:    markerPool{reg: reg, model: OverlayGuardModel},
+		abilities: markerPool{reg: reg, model: OverlayAbilityModel},
+		room:      markerPool{reg: reg, model: OverlayRoomModel},
+	}
+}
+
+// Delete all overlay objects.
+func (o *debugOverlay) Delete() {
+	o.guards.Delete()
+	o.abilities.Delete()
+	o.room.Delete()
+}
+
+// ring adds markers for a circle to the pool.
+func (o *debugOverlay) ring(p *markerPool, c ns4.Pointf, r float32) {
+	for _, pos := range arcPoints(nil, c, r, 0, 2*math.Pi, OverlaySpacing) {
+		p.Add(pos)
+	}
+}
+
+// line adds markers for a line to the pool.
+func (o *debugOverlay) line(p *markerPool, p1, p2 ns4.Pointf) {
+	for _, pos := range linePoints(nil, p1, p2, OverlaySpacing) {
+		p.Add(pos)
+	}
+}
+
+// Update redraws the overlay from the current encounter state.
+func (o *debugOverlay) Update(s *State) {
+	o.frame++
+	if o.frame%OverlayInterval != 0 {
+		return
+	}
+	for _, g := range s.bosses {
+		if s.state == BossDead {
+			break // guard units are already deleted
+		}
+		pos := g.unit.Pos()
+		// Guards charge energy within EnergyDist of each other, and the fight starts within BossStartFightDist.
+		if s.state == BossFighting {
+			o.ring(&o.guards, pos, EnergyDist)
+		} else {
+			o.ring(&o.guards, pos, BossStartFightDist)
+		}
+		switch a := g.abil.(type) {
+		case *RedAbility:
+			for _, sp := range a.active {
+				o.ring(&o.abilities, pos, RedTargetMinDist)
+				o.ring(&o.abilities, pos, RedTargetMaxDist)
+				o.line(&o.abilities, pos, sp.pos)
+			}
+		case *BlueAbility:
+			for _, sp := range a.active {
+				o.ring(&o.abilities, sp.target, BlueOuterR)
+				o.ring(&o.abilities, sp.target, BlueInnerR)
+			}
+		case *GreenAbility:
+			for _, sp := range a.active {
+				if sp.proj != nil {
+					o.ring(&o.abilities, sp.pos, GreenProjKickDist)
+				}
+			}
+		}
+	}
+	// The walls used by hitsWall and leashing.
+	for i := range wallPoints {
+		o.line(&o.room, wallPoints[i], wallPoints[(i+1)%len(wallPoints)])
+	}
+	// The InRoom boundary.
+	o.line(&o.room,
+		ns4.Ptf((roomInEdge+roomSideEdge)/2, (roomInEdge-roomSideEdge)/2),
+		ns4.Ptf((roomInEdge-roomSideEdge)/2, (roomInEdge+roomSideEdge)/2),
+	)
+	o.guards.Flush()
+	o.abilities.Flush()
+	o.room.Flush()
+}
+
+// overlayUpdate shows or hides the debug overlay, depending on Debug and DebugOverlay flags.
+func (s *State) overlayUpdate() {
+	if !Debug || !DebugOverlay {
+		if s.overlay != nil {
+			s.overlay.Delete()
+			s.overlay = nil
+		}
+		return
+	}
+	if s.overlay == nil {
+		s.overlay = newDebugOverlay(&s.objs)
+	}
+	s.overlay.Update(s)
+}
@@ -0,0 +1,238 @@
+package stoneguard
+
+// This file contains patterns for the global room effects.
+// Each pattern draws the effect across the room and defines which area is covered by it,
+// so that gameplay effects are only applied where the pattern is.
+
+import (
+	"math"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+// roomArea describes a room placed on a diagonal.
+// Room coordinates are (a, b), where a is a distance along the diagonal starting from axisStart,
+// and b is an offset perpendicular to the diagonal.
+type roomArea struct {
+	axisStart ns4.Pointf
+	length    float32
+	width     float32
+}
+
+// Point converts room coordinates to a map position.
+func (r roomArea) Point(a, b float32) ns4.Pointf {
+	return r.axisStart.Add(ns4.Ptf(a-b, a+b))
+}
+
+// Coords converts map position to room coordinates.
+func (r roomArea) Coords(pos ns4.Pointf) (a, b float32) {
+	d := pos.Sub(r.axisStart)
+	return (d.X + d.Y) / 2, (d.Y - d.X) / 2
+}
+
+// Center returns the center of the room.
+func (r roomArea) Center() ns4.Pointf {
+	return r.Point(r.length/2, 0)
+}
+
+// EffectPattern is a generator for the room effect visuals.
+type EffectPattern interface {
+	// Draw displays the pattern for a given effect duration (in frames) and power.
+	Draw(e Element, r roomArea, df, power int)
+	// Covers checks if the position is covered by the pattern.
+	Covers(r roomArea, pos ns4.Pointf, df, power int) bool
+}
+
+// roomPatterns sets patterns for each element and power level.
+// If the power is higher than the number of patterns, the last one is used.
+var roomPatterns = map[Element][]EffectPattern{
+	Red:   {linesPattern{}, linesPattern{}, sweepPattern{}, sweepPattern{}},
+	Green: {linesPattern{}, ringPattern{}, ringPattern{}, spiralPattern{}},
+	Blue:  {linesPattern{}, checkerPattern{}, checkerPattern{}, spiralPattern{}},
+}
+
+// roomPattern returns a pattern for a given element and power.
+func roomPattern(e Element, power int) EffectPattern {
+	list := roomPatterns[e]
+	if len(list) == 0 {
+		return linesPattern{}
+	}
+	if power < 0 {
+		power = 0
+	}
+	if power >= len(list) {
+		power = len(list) - 1
+	}
+	return list[power]
+}
+
+// drawRoomEffect draws the room effect with a pattern that corresponds to the element and power.
+func drawRoomEffect(e Element, df, power int, r roomArea) {
+	roomPattern(e, power).Draw(e, r, df, power)
+}
+
+// patternDensity chooses effect density based on the current effect power.
+// It returns true if the effect should be drawn on this frame.
+func patternDensity(df, power int) bool {
+	switch power {
+	case 0:
+		return df%4 == 0 // once per 4 frames
+	case 1:
+		return df%3 == 0 // once per 3 frames
+	case 2:
+		return df%2 == 0 // once per 2 frames
+	}
+	return true // every frame
+}
+
+// drawEffectLine displays a single effect line between two points.
+func drawEffectLine(e Element, p1, p2 ns4.Pointf) {
+	eff := e.RoomEffect()
+	switch e {
+	case Red, Blue:
+		ns4.Effect(eff, p1, p2)
+		ns4.Effect(eff, p2, p1)
+	case Green:
+		// it's already bidirectional
+		ns4.Effect(eff, p1, p2)
+	}
+}
+
+// linesPattern draws lines perpendicular to the room diagonal at random offsets. It covers the whole room.
+type linesPattern struct{}
+
+func (linesPattern) Draw(e Element, r roomArea, df, power int) {
+	if !patternDensity(df, power) {
+		return
+	}
+	// Pick random point across the diagonal.
+	a := float32(ns4.Random(0, int(r.length)))
+	// Effect crosses the whole room, perpendicular to diagonal.
+	drawEffectLine(e, r.Point(a, -r.width/2), r.Point(a, +r.width/2))
+}
+
+func (linesPattern) Covers(r roomArea, pos ns4.Pointf, df, power int) bool {
+	return true
+}
+
+// sweepPattern draws a wall that sweeps back and forth along the room diagonal.
+type sweepPattern struct{}
+
+// pos returns the current wall position along the diagonal.
+func (sweepPattern) pos(r roomArea, df int) float32 {
+	a := math.Mod(float64(df)*PatternSweepSpeed, 2*float64(r.length))
+	if a > float64(r.length) {
+		a = 2*float64(r.length) - a
+	}
+	return float32(a)
+}
+
+func (p sweepPattern) Draw(e Element, r roomArea, df, power int) {
+	if df%2 != 0 {
+		return
+	}
+	a := p.pos(r, df)
+	drawEffectLine(e, r.Point(a, -r.width/2), r.Point(a, +r.width/2))
+}
+
+func (p sweepPattern) Covers(r roomArea, pos ns4.Pointf, df, power int) bool {
+	a, _ := r.Coords(pos)
+	return float32(math.Abs(float64(a-p.pos(r, df)))) < PatternSweepWidth
+}
+
+// ringPattern draws rings expanding from the room center.
+type ringPattern struct{}
+
+// radius returns the current ring radius.
+func (ringPattern) radius(r roomArea, df int) float32 {
+	return float32(math.Mod(float64(df)*PatternRingSpeed, float64(r.length)/2))
+}
+
+func (p ringPattern) Draw(e Element, r roomArea, df, power int) {
+	if df%2 != 0 {
+		return
+	}
+	c, rad := r.Center(), p.radius(r, df)
+	// Draw one segment of the ring per frame, so the effect count stays low.
+	i := (df / 2) % PatternRingSegments
+	ph1 := float64(i) * 2 * math.Pi / PatternRingSegments
+	ph2 := float64(i+1) * 2 * math.Pi / PatternRingSegments
+	p1 := c.Add(ns4.Ptf(rad*float32(math.Cos(ph1)), rad*float32(math.Sin(ph1))))
+	p2 := c.Add(ns4.Ptf(rad*float32(math.Cos(ph2)), rad*float32(math.Sin(ph2))))
+	drawEffectLine(e, p1, p2)
+}
+
+func (p ringPattern) Covers(r roomArea, pos ns4.Pointf, df, power int) bool {
+	d := float32(pos.Sub(r.Center()).Len())
+	return float32(math.Abs(float64(d-p.radius(r, df)))) < PatternRingWidth
+}
+
+// checkerPattern draws checkerboard tiles that switch periodically.
+type checkerPattern struct{}
+
+// phase returns which set of tiles is active.
+func (checkerPattern) phase(df int) int {
+	return (df / (PatternCheckerPeriod * ns4.FrameRate())) % 2
+}
+
+func (p checkerPattern) Draw(e Element, r roomArea, df, power int) {
+	if !patternDensity(df, power) {
+		return
+	}
+	// Pick random active tile and draw a cross in it.
+	cols, rows := int(r.length/PatternCheckerTile), int(r.width/PatternCheckerTile)
+	if cols <= 0 || rows <= 0 {
+		return
+	}
+	i, j := ns4.Random(0, cols-1), ns4.Random(0, rows-1)
+	if (i+j)%2 != p.phase(df) {
+		j = (j + 1) % rows
+		if (i+j)%2 != p.phase(df) {
+			return
+		}
+	}
+	a0, b0 := float32(i)*PatternCheckerTile, float32(j)*PatternCheckerTile-r.width/2
+	drawEffectLine(e, r.Point(a0, b0), r.Point(a0+PatternCheckerTile, b0+PatternCheckerTile))
+	drawEffectLine(e, r.Point(a0+PatternCheckerTile, b0), r.Point(a0, b0+PatternCheckerTile))
+}
+
+func (p checkerPattern) Covers(r roomArea, pos ns4.Pointf, df, power int) bool {
+	a, b := r.Coords(pos)
+	i, j := int(math.Floor(float64(a/PatternCheckerTile))), int(math.Floor(float64((b+r.width/2)/PatternCheckerTile)))
+	return ((i+j)%2+2)%2 == p.phase(df)
+}
+
+// spiralPattern draws arms rotating around the room center.
+type spiralPattern struct{}
+
+// angle returns the current rotation angle of the first arm.
+func (spiralPattern) angle(df int) float64 {
+	return float64(df) * PatternSpiralSpeed
+}
+
+func (p spiralPattern) Draw(e Element, r roomArea, df, power int) {
+	if df%2 != 0 {
+		return
+	}
+	c := r.Center()
+	rad := r.length / 2
+	arm := (df / 2) % PatternSpiralArms
+	ph := p.angle(df) + float64(arm)*2*math.Pi/PatternSpiralArms
+	drawEffectLine(e, c, c.Add(ns4.Ptf(rad*float32(math.Cos(ph)), rad*float32(math.Sin(ph)))))
+}
+
+func (p spiralPattern) Covers(r roomArea, pos ns4.Pointf, df, power int) bool {
+	vec := pos.Sub(r.Center())
+	d := vec.Len()
+	phi := math.Atan2(float64(vec.Y), float64(vec.X)) - p.angle(df)
+	// Find angular distance to the closest arm.
+	step := 2 * math.Pi / PatternSpiralArms
+	dphi := math.Mod(phi, step)
+	if dphi < 0 {
+		dphi += step
+	}
+	if dphi > step/2 {
+		dphi = step - dphi
+	}
+	return d*math.Sin(dphi) < PatternSpiralWidth
+}
@@ -0,0 +1,142 @@
+package stoneguard
+
+import (
+	"math"
+	"testing"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+// roomGrid returns points in room coordinates that cover the whole room, including its edges.
+func roomGrid(r roomArea, step float32) [][2]float32 {
+	var out [][2]float32
+	for a := float32(0); a <= r.length; a += step {
+		for b := -r.width / 2; b <= r.width/2; b += step {
+			out = append(out, [2]float32{a, b})
+		}
+	}
+	return out
+}
+
+func TestRoomAreaCoords(t *testing.T) {
+	r := mainRoom
+	for _, p := range roomGrid(r, 23) {
+		pos := r.Point(p[0], p[1])
+		a, b := r.Coords(pos)
+		if math.Abs(float64(a-p[0])) > 0.01 || math.Abs(float64(b-p[1])) > 0.01 {
+			t.Fatalf("coords do not match: (%v, %v) -> %v -> (%v, %v)", p[0], p[1], pos, a, b)
+		}
+	}
+	if c := r.Center(); c != roomCenter {
+		t.Fatalf("room center does not match: %v vs %v", c, roomCenter)
+	}
+}
+
+func TestRoomAreaInsideWalls(t *testing.T) {
+	// Room effects are drawn over mainRoom, so it must not cross the room walls.
+	for _, p := range roomGrid(mainRoom, 23) {
+		if pos := mainRoom.Point(p[0], p[1]); !inRoomArea(pos, 0) {
+			t.Fatalf("point (%v, %v) is outside the room: %v", p[0], p[1], pos)
+		}
+	}
+}
+
+func TestRoomPattern(t *testing.T) {
+	cases := []struct {
+		elem  Element
+		power int
+		exp   EffectPattern
+	}{
+		{Red, -1, linesPattern{}},
+		{Red, 0, linesPattern{}},
+		{Red, 2, sweepPattern{}},
+		{Red, 10, sweepPattern{}},
+		{Green, 1, ringPattern{}},
+		{Green, 3, spiralPattern{}},
+		{Blue, 1, checkerPattern{}},
+		{Blue, 3, spiralPattern{}},
+		{Element(-1), 0, linesPattern{}},
+	}
+	for _, c := range cases {
+		if got := roomPattern(c.elem, c.power); got != c.exp {
+			t.Errorf("unexpected pattern for %d/%d: %T, expected %T", c.elem, c.power, got, c.exp)
+		}
+	}
+}
+
+func TestPatternCovers(t *testing.T) {
+	r := mainRoom
+	half := r.length / 2
+	// Frame at which the sweeping wall is in the middle of the room.
+	sweepMid := int(half / PatternSweepSpeed)
+	// Frame at which the ring radius is 100.
+	ring100 := int(100 / PatternRingSpeed)
+	checker := PatternCheckerPeriod * ns4.FrameRate()
+	cases := []struct {
+		name string
+		p    EffectPattern
+		df   int
+		pos  ns4.Pointf
+		exp  bool
+	}{
+		{"lines near", linesPattern{}, 0, r.Point(0, 0), true},
+		{"lines far", linesPattern{}, 0, r.Point(r.length, r.width/2), true},
+
+		{"sweep start", sweepPattern{}, 0, r.Point(0, r.width/2), true},
+		{"sweep start far", sweepPattern{}, 0, r.Point(PatternSweepWidth+1, 0), false},
+		{"sweep middle", sweepPattern{}, sweepMid, r.Point(half, -r.width/2), true},
+		{"sweep middle edge", sweepPattern{}, sweepMid, r.Point(half+PatternSweepWidth-1, 0), true},
+		{"sweep middle start", sweepPattern{}, sweepMid, r.Point(0, 0), false},
+		{"sweep back", sweepPattern{}, 3 * sweepMid, r.Point(half, 0), true},
+
+		{"ring on", ringPattern{}, ring100, roomCenter.Add(ns4.Ptf(100, 0)), true},
+		{"ring inside", ringPattern{}, ring100, roomCenter.Add(ns4.Ptf(100-PatternRingWidth-1, 0)), false},
+		{"ring outside", ringPattern{}, ring100, roomCenter.Add(ns4.Ptf(0, 100+PatternRingWidth+1)), false},
+
+		{"checker first", checkerPattern{}, 0, r.Point(1, -r.width/2+1), true},
+		{"checker next", checkerPattern{}, 0, r.Point(PatternCheckerTile+1, -r.width/2+1), false},
+		{"checker switched", checkerPattern{}, checker, r.Point(PatternCheckerTile+1, -r.width/2+1), true},
+		{"checker before axis", checkerPattern{}, 0, r.Point(-1, -r.width/2+1), false},
+
+		{"spiral center", spiralPattern{}, 0, roomCenter, true},
+		{"spiral arm", spiralPattern{}, 0, roomCenter.Add(ns4.Ptf(300, 0)), true},
+		{"spiral between arms", spiralPattern{}, 0, roomCenter.Add(ns4.Ptf(200, 200)), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.p.Covers(r, c.pos, c.df, 0); got != c.exp {
+				t.Fatalf("unexpected coverage of %v on frame %d: %v", c.pos, c.df, got)
+			}
+		})
+	}
+}
+
+func TestPatternCoversPartOfRoom(t *testing.T) {
+	// Patterns other than lines must leave safe spots in the room, but must not miss it completely.
+	for _, p := range []EffectPattern{sweepPattern{}, ringPattern{}, checkerPattern{}, spiralPattern{}} {
+		for df := 0; df < 10*ns4.FrameRate(); df += 7 {
+			covered, total := 0, 0
+			for _, pt := range roomGrid(mainRoom, 23) {
+				total++
+				if p.Covers(mainRoom, mainRoom.Point(pt[0], pt[1]), df, 0) {
+					covered++
+				}
+			}
+			if covered == 0 || covered == total {
+				t.Fatalf("%T on frame %d covers %d of %d points", p, df, covered, total)
+			}
+		}
+	}
+}
+
+func TestCheckerPhases(t *testing.T) {
+	// Each point in the room must be covered in exactly one of the two phases.
+	next := PatternCheckerPeriod * ns4.FrameRate()
+	p := checkerPattern{}
+	for _, pt := range roomGrid(mainRoom, 17) {
+		pos := mainRoom.Point(pt[0], pt[1])
+		if p.Covers(mainRoom, pos, 0, 0) == p.Covers(mainRoom, pos, next, 0) {
+			t.Fatalf("point (%v, %v) is covered in both or none of the phases", pt[0], pt[1])
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package stoneguard
+
+// This file contains the petrification mechanic.
+// Active room effect gradually petrifies players, and only the explosion of the matching guard resets it.
+// Each color/element petrifies separately: switching the effect on timeout keeps the petrification,
+// and the matching explosion only removes the part caused by its own element.
+
+import (
+	"fmt"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+	"github.com/noxworld-dev/noxscript/ns/v4/damage"
+)
+
+// petrifyMeter stores petrification state of a single player.
+type petrifyMeter struct {
+	unit  ns4.Obj
+	value [colorMax]int // meter value caused by each color/element
+	level int           // current slow level
+	speed float32       // original base speed of the player
+}
+
+// Value returns the total meter value.
+func (m *petrifyMeter) Value() int {
+	total := 0
+	for _, v := range m.value {
+		total += v
+	}
+	return total
+}
+
+// Level returns current petrification level of the player, based on the meter value.
+func (m *petrifyMeter) Level() int {
+	return m.Value() / PetrifyStep
+}
+
+// setLevel adjusts player speed to a given petrification level.
+func (m *petrifyMeter) setLevel(lvl int) {
+	if lvl == m.level {
+		return
+	}
+	m.level = lvl
+	perc := 100 - lvl*PetrifySpeedStep
+	if perc < 0 {
+		perc = 0
+	}
+	m.unit.SetBaseSpeed(m.speed * float32(perc) / 100)
+}
+
+// petrifyFor returns petrification meter for a player, creating it if necessary.
+func (s *State) petrifyFor(u ns4.Obj) *petrifyMeter {
+	for _, m := range s.petrify {
+		if m.unit == u {
+			return m
+		}
+	}
+	m := &petrifyMeter{unit: u, speed: u.BaseSpeed()}
+	s.petrify = append(s.petrify, m)
+	return m
+}
+
+// petrifyUpdate increases petrification of a player by the room effect of a given color/element,
+// according to the effect power. Full meter is lethal.
+func (s *State) petrifyUpdate(u ns4.Obj, c Element, power int) {
+	m := s.petrifyFor(u)
+	m.value[c] += PetrifyRate * (power + 1)
+	if m.Value() >= PetrifyMax {
+		if Debug {
+			fmt.Printf("Player %s is petrified!\n", playerName(u))
+		}
+		u.Damage(nil, u.MaxHealth()*2, damage.DEATH_MAGIC)
+		m.value = [colorMax]int{}
+	}
+	m.setLevel(m.Level())
+}
+
+// clearPetrifyElement removes petrification caused by a given color/element from all players.
+func (s *State) clearPetrifyElement(c Element) {
+	for _, m := range s.petrify {
+		m.value[c] = 0
+		m.setLevel(m.Level())
+	}
+}
+
+// clearPetrify resets petrification of all players and restores their speed.
+func (s *State) clearPetrify() {
+	for _, m := range s.petrify {
+		m.value = [colorMax]int{}
+		m.setLevel(0)
+	}
+	s.petrify = nil
+}
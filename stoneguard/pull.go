@@ -0,0 +1,129 @@
+package stoneguard
+
+// This file contains the ready-check and the pull countdown logic.
+
+import (
+	"fmt"
+	"strings"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+	"github.com/noxworld-dev/opennox-lib/object"
+
+	"mogushan/ui"
+)
+
+// onPullChat starts the ready-check when a player types PullCommand in chat.
+func (s *State) onPullChat(t ns4.Team, p ns4.Player, obj ns4.Obj, msg string) string {
+	if p == nil || strings.TrimSpace(msg) != PullCommand {
+		return msg
+	}
+	if !PullReadyCheck || s.state != BossWaiting {
+		p.PrintStr("The fight cannot be started now.")
+		return msg
+	}
+	s.startCountdown(p)
+	return msg
+}
+
+// pullCountdownStyle is a style of the pull countdown shown above each guard.
+var pullCountdownStyle = ui.BarStyle{
+	Orientation: ui.Horizontal,
+	Length:      56,
+	Slots:       PullCountdown * 2,
+	Offset:      ns4.Pointf{X: 0, Y: -62},
+	Models:      []string{"DrainManaOrb"},
+}
+
+// startCountdown switches boss state to BossCountdown.
+func (s *State) startCountdown(p ns4.Player) {
+	ns4.PrintStrToAll(fmt.Sprintf("%s is ready to fight the Stone Guard!", p.Name()))
+	s.frame = 0
+	s.state = BossCountdown
+	s.deleteLever()
+	// players see the countdown above each guard
+	for _, g := range s.bosses {
+		c := ui.NewCountdown(g.unit, pullCountdownStyle, PullCountdown*ns4.FrameRate())
+		s.objs.TrackSource("pull countdown", c)
+		s.pullTimers = append(s.pullTimers, c)
+	}
+}
+
+// stopCountdown deletes the pull countdown timers.
+func (s *State) stopCountdown() {
+	for _, c := range s.pullTimers {
+		s.objs.DeleteSource(c)
+	}
+	s.pullTimers = nil
+}
+
+// countdownUpdate is the update function for the BossCountdown state.
+func (s *State) countdownUpdate() {
+	if s.isCharmed() {
+		s.Reset()
+		return
+	}
+	done := len(s.pullTimers) == 0
+	for _, c := range s.pullTimers {
+		c.Update()
+		done = done || c.Done()
+	}
+	if done {
+		ns4.PrintStrToAll("Fight!")
+		s.startFight()
+		return
+	}
+	s.frame++
+}
+
+// spawnLever creates the lever that starts the ready-check, if enabled.
+func (s *State) spawnLever() {
+	if !PullReadyCheck || !PullLever || s.lever != nil {
+		return
+	}
+	s.lever = s.objs.Create("pull lever", PullLeverModel, pullLeverPos)
+	if s.lever != nil {
+		s.leverOn = s.lever.IsEnabled()
+	}
+}
+
+// deleteLever deletes the ready-check lever.
+func (s *State) deleteLever() {
+	if s.lever == nil {
+		return
+	}
+	s.objs.Delete(s.lever)
+	s.lever = nil
+}
+
+// leverUpdate starts the ready-check when one of the players switches the lever.
+func (s *State) leverUpdate() {
+	if s.lever == nil {
+		return
+	}
+	on := s.lever.IsEnabled()
+	if on == s.leverOn {
+		return
+	}
+	s.leverOn = on
+	// the lever does not tell who used it, so the closest player is announced
+	u := ns4.FindClosestObject(s.lever, ns4.HasClass(object.ClassPlayer))
+	if u == nil || u.Player() == nil {
+		return
+	}
+	s.startCountdown(u.Player())
+}
+
+// pullWarning warns the player that came too close to the bosses before the ready-check.
+func (s *State) pullWarning(u ns4.Obj) {
+	if df := ns4.Frame() - s.pullWarnAt; df >= 0 && df < PullWarnInterval*ns4.FrameRate() {
+		return
+	}
+	s.pullWarnAt = ns4.Frame()
+	if pl := u.Player(); pl != nil {
+		if s.lever != nil {
+			pl.PrintStr(fmt.Sprintf("Step back! Use the lever or type %s in chat to start the fight.", PullCommand))
+		} else {
+			pl.PrintStr(fmt.Sprintf("Step back! Type %s in chat to start the fight.", PullCommand))
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package stoneguard
+
+// This file contains the stationary raid frame placed in the boss room.
+// It shows the shared boss health, current room effect, its power and time until timeout.
+
+import (
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+
+	"mogushan/ui"
+)
+
+// raidFramePos is a position of the raid frame, along the far wall of the room.
+var raidFramePos = ns4.Ptf(4230, 4230)
+
+var (
+	// raidHealthStyle is a style of the shared boss health bar.
+	raidHealthStyle = ui.BarStyle{
+		Orientation: ui.Horizontal,
+		Length:      184,
+		Slots:       16,
+		Models:      []string{"DrainManaOrb", "HealOrb"},
+		EmptyModel:  "WhiteOrb",
+	}
+	// raidPowerStyle is a style of the room effect power bar.
+	raidPowerStyle = ui.BarStyle{
+		Orientation: ui.Horizontal,
+		Length:      92,
+		Slots:       RoomEffectTimeout / RoomEffectPowerInterval,
+		Offset:      ns4.Pointf{X: -46, Y: 23},
+		Models:      []string{"WhiteOrb"},
+	}
+	// raidTimeoutStyle is a style of the room effect timeout bar.
+	raidTimeoutStyle = ui.BarStyle{
+		Orientation: ui.Horizontal,
+		Length:      184,
+		Slots:       16,
+		Offset:      ns4.Pointf{Y: 46},
+		Models:      []string{"DrainManaOrb", "WhiteOrb"},
+	}
+)
+
+// raidFrame is a stationary display of the fight state.
+type raidFrame struct {
+	s       *State
+	health  *ui.ProgressBar
+	power   *ui.ProgressBar
+	timeout *ui.ProgressBar
+}
+
+// newRaidFrame creates the raid frame at raidFramePos.
+func (s *State) newRaidFrame() *raidFrame {
+	f := &raidFrame{
+		s:       s,
+		health:  ui.NewProgressBar(raidFramePos, raidHealthStyle),
+		power:   ui.NewProgressBar(raidFramePos, raidPowerStyle),
+		timeout: ui.NewProgressBar(raidFramePos, raidTimeoutStyle),
+	}
+	s.objs.TrackSource("raid frame", f.health)
+	s.objs.TrackSource("raid frame", f.power)
+	s.objs.TrackSource("raid frame", f.timeout)
+	return f
+}
+
+// Delete the raid frame objects.
+func (f *raidFrame) Delete() {
+	f.s.objs.DeleteSource(f.health)
+	f.s.objs.DeleteSource(f.power)
+	f.s.objs.DeleteSource(f.timeout)
+}
+
+// Update the raid frame from the fight state.
+func (f *raidFrame) Update(s *State) {
+	f.health.Set(float32(s.health) / float32(BossHealth))
+	if s.curEffect < 0 {
+		f.power.Set(0)
+		f.timeout.Set(0)
+	} else {
+		df := s.frame - s.roomEffectStart
+		f.power.Set(float32(s.roomEffectPower()+1) / float32(raidPowerStyle.Slots))
+		f.timeout.Set(1 - float32(df)/float32(s.roomEffectTimeout()))
+		// Show current room effect as a colored marker next to the power bar.
+		if s.frame%RaidFrameMarkerInterval == 0 {
+			p := raidFramePos.Add(ns4.Ptf(46, 23))
+			drawEffectLine(s.curEffect, p.Sub(ns4.Ptf(23, 0)), p.Add(ns4.Ptf(23, 0)))
+		}
+	}
+	f.health.Update()
+	f.power.Update()
+	f.timeout.Update()
+}
@@ -32,6 +32,9 @@ const (
 	roomWidth = 368
 )
 
+// mainRoom is the boss room area used for the room effects.
+var mainRoom = roomArea{axisStart: roomAxisStart, length: roomLength, width: roomWidth}
+
 // entranceWalls is an array of wall coordinates for the entrance.
 var entranceWalls = [][2]int{
 	{205, 209},
@@ -44,6 +47,12 @@ var entranceWalls = [][2]int{
 // playerPos is a default positions where players will be teleported to when the fight starts.
 var playerPos = ns4.Ptf(4726, 4726)
 
+// graveyardPos is a safe position outside the boss zone entrance where players are moved after a wipe.
+var graveyardPos = ns4.Ptf(5175, 5175)
+
+// pullLeverPos is a position of the ready-check lever, next to the graveyard.
+var pullLeverPos = ns4.Ptf(5129, 5198)
+
 // switchEntrance switches boss zone entrance on/off.
 func (s *State) switchEntrance(open bool) {
 	for _, pos := range entranceWalls {
@@ -57,13 +66,13 @@ func (s *State) InRoom(pl ns4.Obj) bool {
 		return false
 	}
 	pos := pl.Pos()
-	return pos.X+pos.Y < 9820
+	return pos.X+pos.Y < roomInEdge
 }
 
-// teleportPlayersToRoom teleports players that are not in the room already to playerPos.
+// teleportPlayersToRoom teleports participants that are not in the room already to playerPos.
 func (s *State) teleportPlayersToRoom() {
-	for _, pl := range ns4.Players() {
-		u := pl.Unit()
+	for _, e := range s.roster {
+		u := e.pl.Unit()
 		if u != nil && !s.InRoom(u) {
 			u.SetPos(playerPos)
 		}
@@ -82,14 +91,36 @@ func (s *State) randomBossPos() [3]types.Pointf {
 func (s *State) nextRoomEffect() {
 	// do not allow the same effect to play twice
 	prev := s.curEffect
-	for {
-		s.curEffect = Element(ns4.Random(0, 3)) % colorMax
-		if prev != s.curEffect {
-			break
-		}
+	next := prev
+	for next == prev {
+		next = Element(ns4.Random(0, 3)) % colorMax
 	}
+	s.setRoomEffect(next)
+}
+
+// setRoomEffect sets a given global room effect.
+func (s *State) setRoomEffect(e Element) {
+	s.curEffect = e
 	s.roomEffectStart = s.frame
 	s.firstEffect = false
+	s.Announce(PriorityNormal, msgEffectSwitch, s.curEffect.EffectName())
+}
+
+// roomEffectTimeout returns the duration of the current room effect in frames.
+func (s *State) roomEffectTimeout() int {
+	timeout := RoomEffectTimeout
+	if s.firstEffect {
+		timeout = RoomEffectFirstTimeout
+	}
+	return timeout * ns4.FrameRate()
+}
+
+// roomEffectPower returns the power of the current room effect.
+func (s *State) roomEffectPower() int {
+	// Power rises as the time passes.
+	// Due to integer division, it will rise in steps.
+	df := s.frame - s.roomEffectStart
+	return df / (RoomEffectPowerInterval * ns4.FrameRate())
 }
 
 // roomEffectUpdate updates the global boss room effect.
@@ -105,73 +136,35 @@ func (s *State) roomEffectUpdate() {
 	df := s.frame - s.roomEffectStart
 
 	// Check if effect should timeout.
-	timeout := RoomEffectTimeout
-	if s.firstEffect {
-		timeout = RoomEffectFirstTimeout
-	}
-	if df > timeout*ns4.FrameRate() {
+	if df > s.roomEffectTimeout() {
 		// Switch effect and confuse players.
 		fmt.Println("Effect timeout!")
+		notify(EventEffectTimeout, 1)
 		s.nextRoomEffect()
+		s.Announce(PriorityHigh, msgEffectTimeout)
 		s.EachPlayerInRoom(func(u ns4.Obj) {
 			u.Enchant(enchant.CONFUSED, ns4.Seconds(RoomEffectTimeoutConfuse))
 		})
 		return
 	}
-	// Power rises as the time passes.
-	// Due to integer division, it will rise in steps.
-	power := df / (RoomEffectPowerInterval * ns4.FrameRate())
+	power := s.roomEffectPower()
 
 	// Print effect power for debugging.
 	if Debug && s.frame%(RoomEffectPowerReport*ns4.FrameRate()) == 0 {
 		fmt.Printf("Effect power: %d\n", power)
 	}
-	drawRoomEffect(s.curEffect, df, power, roomAxisStart, roomLength, roomWidth)
-}
-
-func drawRoomEffect(e Element, df, power int, axisStart types.Pointf, roomW, roomH int) {
-	// Choose effect density based on the current effect power.
-	var cnt int
-	switch power {
-	case 0:
-		if df%4 == 0 { // once per 4 frames
-			cnt = 1
-		}
-	case 1:
-		if df%3 == 0 { // once per 3 frames
-			cnt = 1
-		}
-	case 2:
-		if df%2 == 0 { // once per 2 frames
-			cnt = 1
-		}
-	default:
-		cnt = 1 // every frame
-	}
+	pattern := roomPattern(s.curEffect, power)
+	pattern.Draw(s.curEffect, mainRoom, df, power)
 
-	eff := e.RoomEffect()
-	// Spawn 'cnt' effects across the room.
-	for i := 0; i < cnt; i++ {
-		// Room is placed right on the diagonal.
-		p0 := axisStart
-
-		// Pick random point across the diagonal.
-		v := float32(ns4.Random(0, roomW))
-		p0 = p0.Add(ns4.Ptf(v, v))
-
-		// Effect crosses the whole room, perpendicular to diagonal.
-		p1 := p0.Add(ns4.Ptf(-float32(roomH)/2, +float32(roomH)/2))
-		p2 := p0.Add(ns4.Ptf(+float32(roomH)/2, -float32(roomH)/2))
-
-		// Display the actual effect.
-		switch e {
-		case Red, Blue:
-			ns4.Effect(eff, p1, p2)
-			ns4.Effect(eff, p2, p1)
-		case Green:
-			// it's already bidirectional
-			ns4.Effect(eff, p1, p2)
-		}
+	// Apply the room effect debuff to players periodically, but only where the pattern is.
+	if df%(RoomEffectTickInterval*ns4.FrameRate()) == 0 {
+		s.EachPlayerInRoom(func(u ns4.Obj) {
+			if !pattern.Covers(mainRoom, u.Pos(), df, power) {
+				return
+			}
+			s.curEffect.ApplyRoomEffect(u, power)
+			s.petrifyUpdate(u, s.curEffect, power)
+		})
 	}
 }
 
@@ -196,16 +189,44 @@ var (
 	phiClose = math.Atan2(float64(walls[3].Y), float64(walls[3].X))
 )
 
+const (
+	// roomFarEdge is a X+Y coordinate sum of the far room wall.
+	roomFarEdge = 8371
+	// roomCloseEdge is a X+Y coordinate sum of the close room wall.
+	roomCloseEdge = 9797
+	// roomSideEdge is a X-Y coordinate difference of the side room walls (negative for the left one).
+	roomSideEdge = 391
+	// roomInEdge is a X+Y coordinate sum of the boundary used by InRoom.
+	roomInEdge = 9820
+)
+
+// roomCenter is a center point of the boss room.
+var roomCenter = ns4.Ptf((roomFarEdge+roomCloseEdge)/4, (roomFarEdge+roomCloseEdge)/4)
+
+// roomRect is a bounding rectangle of the boss room, used to limit object searches to the room.
+var roomRect = ns4.InRectf{
+	Min: ns4.Ptf((roomFarEdge-roomSideEdge)/2, (roomFarEdge-roomSideEdge)/2),
+	Max: ns4.Ptf((roomInEdge+roomSideEdge)/2, (roomInEdge+roomSideEdge)/2),
+}
+
+// inRoomArea checks if the position is inside the room walls, at least margin units away from them.
+func inRoomArea(pos ns4.Pointf, margin float32) bool {
+	return pos.X+pos.Y >= roomFarEdge+margin &&
+		pos.X+pos.Y <= roomCloseEdge-margin &&
+		pos.X-pos.Y <= roomSideEdge-margin &&
+		pos.X-pos.Y >= -roomSideEdge+margin
+}
+
 func hitsWall(pos, vec ns4.Pointf) (ns4.Pointf, bool) {
 	phi := math.Atan2(float64(vec.Y), float64(vec.X))
 	var wph float64
-	if pos.X+pos.Y < 8371 {
+	if pos.X+pos.Y < roomFarEdge {
 		wph = phiFar
-	} else if pos.X+pos.Y > 9797 {
+	} else if pos.X+pos.Y > roomCloseEdge {
 		wph = phiClose
-	} else if pos.X-pos.Y > 391 {
+	} else if pos.X-pos.Y > roomSideEdge {
 		wph = phiRight
-	} else if pos.X-pos.Y < -391 {
+	} else if pos.X-pos.Y < -roomSideEdge {
 		wph = phiLeft
 	} else {
 		return vec, false
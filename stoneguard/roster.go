@@ -0,0 +1,120 @@
+package stoneguard
+
+// This file contains the participant roster, which is locked when the fight starts.
+// Players that were not part of the pull are held outside until the fight ends.
+
+import (
+	"fmt"
+	"strings"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+// RosterStatus is a status of the fight participant.
+type RosterStatus int
+
+const (
+	RosterAlive = RosterStatus(iota)
+	RosterDead
+	RosterDisconnected
+)
+
+func (st RosterStatus) String() string {
+	switch st {
+	case RosterAlive:
+		return "alive"
+	case RosterDead:
+		return "dead"
+	case RosterDisconnected:
+		return "disconnected"
+	}
+	return fmt.Sprintf("RosterStatus(%d)", int(st))
+}
+
+// rosterEntry is a single fight participant.
+type rosterEntry struct {
+	pl     ns4.Player
+	unit   ns4.Obj // player unit at the time of the pull
+	name   string
+	status RosterStatus
+}
+
+// lockRoster adds all players to the roster, except the ones that opted out.
+func (s *State) lockRoster() {
+	s.roster = nil
+	for _, pl := range ns4.Players() {
+		if pl.Unit() == nil || s.optOut[pl.Name()] {
+			continue
+		}
+		s.roster = append(s.roster, &rosterEntry{pl: pl, unit: pl.Unit(), name: pl.Name()})
+	}
+}
+
+// participant returns a roster entry for the player, or nil if the player is not a participant.
+func (s *State) participant(pl ns4.Player) *rosterEntry {
+	for _, e := range s.roster {
+		if e.pl == pl {
+			return e
+		}
+	}
+	return nil
+}
+
+// rosterUpdate updates participant statuses and holds everyone else outside the room.
+// Participants that died or disconnected cannot return to the fight, even if they respawn or reconnect.
+func (s *State) rosterUpdate() {
+	players := ns4.Players()
+	for _, e := range s.roster {
+		if e.status != RosterAlive {
+			continue
+		}
+		online := false
+		for _, pl := range players {
+			if pl == e.pl {
+				online = true
+				break
+			}
+		}
+		if !online {
+			if Debug {
+				fmt.Printf("Participant %s disconnected\n", e.name)
+			}
+			e.status = RosterDisconnected
+		} else if u := e.pl.Unit(); u == nil || u.CurrentHealth() <= 0 {
+			e.status = RosterDead
+		} else {
+			continue
+		}
+		// Let active spells know that the target is gone.
+		s.targetLost(e.unit)
+	}
+	for _, pl := range players {
+		u := pl.Unit()
+		if u == nil || u.CurrentHealth() <= 0 || !s.InRoom(u) {
+			continue
+		}
+		if e := s.participant(pl); e != nil && e.status == RosterAlive {
+			continue
+		}
+		u.SetPos(graveyardPos)
+		s.AnnounceTo(u, PriorityHigh, msgFightInProgress)
+	}
+}
+
+// onOptOutChat toggles whether the player takes part in the next pulls.
+func (s *State) onOptOutChat(t ns4.Team, p ns4.Player, obj ns4.Obj, msg string) string {
+	if p == nil || strings.TrimSpace(msg) != PullOptOutCommand {
+		return msg
+	}
+	if s.optOut == nil {
+		s.optOut = make(map[string]bool)
+	}
+	name := p.Name()
+	s.optOut[name] = !s.optOut[name]
+	if s.optOut[name] {
+		p.PrintStr("You will not be teleported into the Stone Guard fight.")
+	} else {
+		p.PrintStr("You will take part in the next Stone Guard fight.")
+	}
+	return msg
+}
@@ -0,0 +1,89 @@
+package stoneguard
+
+// This file contains the steering layer for the guards.
+// It makes guards move intentionally instead of relying only on the built-in monster AI.
+
+import (
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+// steerUpdate combines all steering behaviours and moves the guard if the result is strong enough.
+// Otherwise, the guard is left to the built-in AI, attacking its threat target.
+func (g *Guard) steerUpdate() {
+	if g.frame%SteerInterval != 0 {
+		return
+	}
+	if !SteerEnabled {
+		// Movement is left to the built-in AI.
+		return
+	}
+	pos := g.unit.Pos()
+	var force ns4.Pointf
+	force = force.Add(g.steerTank(pos).Mul(SteerTankWeight))
+	force = force.Add(g.steerHome(pos).Mul(SteerHomeWeight))
+	force = force.Add(g.steerSeek(pos).Mul(SteerSeekWeight))
+	force = force.Add(g.steerLeash(pos).Mul(SteerLeashWeight))
+	if force.Len() < SteerMinForce {
+		if g.steering {
+			// Steering stopped - resume attacking the threat target on the next threat update.
+			g.steering = false
+			g.threat.target = nil
+		}
+		return
+	}
+	g.steering = true
+	g.unit.WalkTo(pos.Add(force.Normalize().Mul(SteerStep)))
+}
+
+// steerTank returns a direction toward the threat target, if the guard is too far from it.
+func (g *Guard) steerTank(pos ns4.Pointf) ns4.Pointf {
+	targ := g.threat.target
+	if targ == nil {
+		return ns4.Pointf{}
+	}
+	vec := targ.Pos().Sub(pos)
+	if vec.Len() < SteerTankDist {
+		return ns4.Pointf{}
+	}
+	return vec.Normalize()
+}
+
+// steerHome returns a direction toward the spawn point, if the guard has no target.
+func (g *Guard) steerHome(pos ns4.Pointf) ns4.Pointf {
+	if g.threat.target != nil {
+		return ns4.Pointf{}
+	}
+	vec := g.spawn.Sub(pos)
+	if vec.Len() < SteerHomeDist {
+		return ns4.Pointf{}
+	}
+	return vec.Normalize()
+}
+
+// steerSeek returns a direction toward the closest other guard, if it's too far to gather energy.
+func (g *Guard) steerSeek(pos ns4.Pointf) ns4.Pointf {
+	var (
+		closest *Guard
+		dist    float64
+	)
+	for _, b := range g.s.bosses {
+		if b == g {
+			continue
+		}
+		if d := b.unit.Pos().Sub(pos).Len(); closest == nil || d < dist {
+			closest, dist = b, d
+		}
+	}
+	if closest == nil || dist < EnergyDist {
+		return ns4.Pointf{}
+	}
+	return closest.unit.Pos().Sub(pos).Normalize()
+}
+
+// steerLeash returns a direction toward the room center, if the guard is getting close to the room edges.
+func (g *Guard) steerLeash(pos ns4.Pointf) ns4.Pointf {
+	if inRoomArea(pos, SteerLeashMargin) {
+		return ns4.Pointf{}
+	}
+	return roomCenter.Sub(pos).Normalize()
+}
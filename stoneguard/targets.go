@@ -0,0 +1,57 @@
+package stoneguard
+
+// This file contains handling of ability targets that leave the fight while the spell is still active.
+
+import (
+	"fmt"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+	"github.com/noxworld-dev/opennox-lib/object"
+)
+
+// TargetPolicy sets what an active spell does when its target leaves the fight.
+type TargetPolicy int
+
+const (
+	// TargetFinish keeps the spell going at the last known target position.
+	TargetFinish = TargetPolicy(iota)
+	// TargetRetarget moves the spell to a new random target in the room. If there are none, the spell fizzles.
+	TargetRetarget
+	// TargetFizzle cancels the spell.
+	TargetFizzle
+)
+
+func (p TargetPolicy) String() string {
+	switch p {
+	case TargetFinish:
+		return "finish"
+	case TargetRetarget:
+		return "retarget"
+	case TargetFizzle:
+		return "fizzle"
+	}
+	return fmt.Sprintf("TargetPolicy(%d)", int(p))
+}
+
+// targetLost notifies abilities of all guards that the unit left the fight.
+func (s *State) targetLost(u ns4.Obj) {
+	if u == nil {
+		return
+	}
+	for _, g := range s.bosses {
+		if g.abil != nil {
+			g.abil.TargetLost(g, u)
+		}
+	}
+}
+
+// isAlive checks if the unit is still alive and was not removed from the map.
+func isAlive(u ns4.Obj) bool {
+	return !u.Flags().HasAny(object.FlagDead|object.FlagDestroyed) && u.CurrentHealth() > 0
+}
+
+// retarget announces a new target of the ability, after the previous one left the fight.
+func (g *Guard) retarget(u ns4.Obj) {
+	g.s.Announce(PriorityHigh, msgTargets, g.color.GuardName(), playerName(u))
+	g.s.AnnounceTo(u, PriorityHigh, msgTargetsYou, g.color.GuardName())
+}
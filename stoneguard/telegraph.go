@@ -0,0 +1,169 @@
+package stoneguard
+
+// This file contains ground telegraphs that warn players where boss abilities will land.
+
+import (
+	"math"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+// TelegraphShape is a shape of the ground telegraph.
+type TelegraphShape int
+
+const (
+	TelegraphCircle = TelegraphShape(iota)
+	TelegraphCone
+	TelegraphLine
+)
+
+// Telegraph is a ground indicator of the upcoming boss attack.
+type Telegraph struct {
+	s     *State
+	shape TelegraphShape
+	pos   ns4.Pointf // center for circle and cone, start for line
+	end   ns4.Pointf // end of the line
+	dir   ns4.Pointf // direction of the cone
+	r     float32
+	angle float64 // full angle of the cone
+	left  int     // frames left before the ability resolves
+	objs  ns4.Objects
+}
+
+// TelegraphCircle shows a circle with a given radius for dur seconds.
+func (s *State) TelegraphCircle(pos ns4.Pointf, r float32, dur float64) *Telegraph {
+	return s.newTelegraph(&Telegraph{shape: TelegraphCircle, pos: pos, r: r}, dur)
+}
+
+// TelegraphCone shows a cone with a given direction, radius and full angle (in radians) for dur seconds.
+func (s *State) TelegraphCone(pos, dir ns4.Pointf, r float32, angle float64, dur float64) *Telegraph {
+	return s.newTelegraph(&Telegraph{shape: TelegraphCone, pos: pos, dir: dir.Normalize(), r: r, angle: angle}, dur)
+}
+
+// TelegraphLine shows a line between two points for dur seconds.
+func (s *State) TelegraphLine(p1, p2 ns4.Pointf, dur float64) *Telegraph {
+	return s.newTelegraph(&Telegraph{shape: TelegraphLine, pos: p1, end: p2}, dur)
+}
+
+func (s *State) newTelegraph(t *Telegraph, dur float64) *Telegraph {
+	t.s = s
+	t.left = int(dur * float64(ns4.FrameRate()))
+	for _, p := range t.points() {
+		t.objs = append(t.objs, s.objs.Create("telegraphs", TelegraphModel, p))
+	}
+	s.telegraphs = append(s.telegraphs, t)
+	return t
+}
+
+// points returns marker positions for the telegraph shape.
+func (t *Telegraph) points() []ns4.Pointf {
+	var out []ns4.Pointf
+	switch t.shape {
+	case TelegraphCircle:
+		out = arcPoints(out, t.pos, t.r, 0, 2*math.Pi, TelegraphSpacing)
+	case TelegraphCone:
+		phi := math.Atan2(float64(t.dir.Y), float64(t.dir.X))
+		from, to := phi-t.angle/2, phi+t.angle/2
+		out = arcPoints(out, t.pos, t.r, from, to, TelegraphSpacing)
+		out = linePoints(out, t.pos, t.pos.Add(ns4.Ptf(t.r*float32(math.Cos(from)), t.r*float32(math.Sin(from)))), TelegraphSpacing)
+		out = linePoints(out, t.pos, t.pos.Add(ns4.Ptf(t.r*float32(math.Cos(to)), t.r*float32(math.Sin(to)))), TelegraphSpacing)
+	case TelegraphLine:
+		out = linePoints(out, t.pos, t.end, TelegraphSpacing)
+	}
+	return out
+}
+
+// arcPoints adds marker positions on the arc, with a given spacing between them.
+func arcPoints(out []ns4.Pointf, c ns4.Pointf, r float32, from, to float64, spacing float64) []ns4.Pointf {
+	n := int(float64(r)*(to-from)/spacing) + 1
+	for i := 0; i < n; i++ {
+		ph := from + (to-from)*float64(i)/float64(n)
+		out = append(out, c.Add(ns4.Ptf(r*float32(math.Cos(ph)), r*float32(math.Sin(ph)))))
+	}
+	return out
+}
+
+// linePoints adds marker positions on the line, with a given spacing between them.
+func linePoints(out []ns4.Pointf, p1, p2 ns4.Pointf, spacing float64) []ns4.Pointf {
+	vec := p2.Sub(p1)
+	n := int(vec.Len()/spacing) + 1
+	for i := 0; i <= n; i++ {
+		out = append(out, p1.Add(vec.Mul(float32(i)/float32(n))))
+	}
+	return out
+}
+
+// SetPos moves the circle or cone telegraph to a new center.
+func (t *Telegraph) SetPos(pos ns4.Pointf) {
+	if t.Done() {
+		return
+	}
+	t.pos = pos
+	for i, p := range t.points() {
+		t.objs[i].SetPos(p)
+	}
+}
+
+// SetLine moves the line telegraph to new positions. Markers are added or removed if the length changes.
+func (t *Telegraph) SetLine(p1, p2 ns4.Pointf) {
+	if t.Done() {
+		return
+	}
+	t.pos, t.end = p1, p2
+	pts := t.points()
+	for len(t.objs) < len(pts) {
+		t.objs = append(t.objs, t.s.objs.Create("telegraphs", TelegraphModel, p1))
+	}
+	for len(t.objs) > len(pts) {
+		last := len(t.objs) - 1
+		t.s.objs.Delete(t.objs[last])
+		t.objs = t.objs[:last]
+	}
+	for i, p := range pts {
+		t.objs[i].SetPos(p)
+	}
+}
+
+// Done checks if the telegraph expired.
+func (t *Telegraph) Done() bool {
+	return t.left <= 0
+}
+
+// Delete the telegraph markers.
+func (t *Telegraph) Delete() {
+	t.s.objs.Delete(t.objs...)
+	t.objs = nil
+	t.left = 0
+}
+
+// Update the telegraph. Markers start to blink shortly before the ability resolves.
+func (t *Telegraph) Update() {
+	t.left--
+	if t.left <= 0 {
+		t.Delete()
+		return
+	}
+	if t.left < TelegraphBlink*ns4.FrameRate() {
+		t.objs.Enable((t.left/4)%2 == 0)
+	}
+}
+
+// telegraphUpdate updates all telegraphs and removes expired ones.
+func (s *State) telegraphUpdate() {
+	for i := 0; i < len(s.telegraphs); i++ {
+		t := s.telegraphs[i]
+		t.Update()
+		if t.Done() {
+			s.telegraphs = append(s.telegraphs[:i], s.telegraphs[i+1:]...)
+			i--
+		}
+	}
+}
+
+// deleteTelegraphs removes all active telegraphs.
+func (s *State) deleteTelegraphs() {
+	for _, t := range s.telegraphs {
+		t.Delete()
+	}
+	s.telegraphs = nil
+}
@@ -0,0 +1,244 @@
+package stoneguard
+
+// This file contains the threat table for the guards.
+// It decides which player each guard is chasing, so players can control who tanks which guard.
+
+import (
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+// threatEntry stores accumulated threat of a single player.
+type threatEntry struct {
+	unit   ns4.Obj
+	threat int
+}
+
+// threatTable stores threat of all players for a single guard.
+type threatTable struct {
+	list     []threatEntry
+	target   ns4.Obj // current top-threat player the guard is steered toward
+	tauntBy  ns4.Obj // player that taunted the guard last
+	tauntEnd int     // frame at which the taunt ends
+}
+
+// Reset clears all threat.
+func (t *threatTable) Reset() {
+	t.list = nil
+	t.target = nil
+	t.tauntBy = nil
+	t.tauntEnd = 0
+}
+
+// Get returns current threat of a given player.
+func (t *threatTable) Get(u ns4.Obj) int {
+	for _, e := range t.list {
+		if e.unit == u {
+			return e.threat
+		}
+	}
+	return 0
+}
+
+// Add adds threat for a given player.
+func (t *threatTable) Add(u ns4.Obj, threat int) {
+	for i := range t.list {
+		if t.list[i].unit == u {
+			t.list[i].threat += threat
+			return
+		}
+	}
+	t.list = append(t.list, threatEntry{unit: u, threat: threat})
+}
+
+// Set overrides threat for a given player.
+func (t *threatTable) Set(u ns4.Obj, threat int) {
+	for i := range t.list {
+		if t.list[i].unit == u {
+			t.list[i].threat = threat
+			return
+		}
+	}
+	t.list = append(t.list, threatEntry{unit: u, threat: threat})
+}
+
+// Remove removes a given player from the table.
+func (t *threatTable) Remove(u ns4.Obj) {
+	for i := 0; i < len(t.list); i++ {
+		if t.list[i].unit == u {
+			t.list = append(t.list[:i], t.list[i+1:]...)
+			i--
+		}
+	}
+	if t.target == u {
+		t.target = nil
+	}
+	if t.tauntBy == u {
+		t.tauntBy = nil
+	}
+}
+
+// Top returns a player with the highest threat.
+func (t *threatTable) Top() (ns4.Obj, int) {
+	var (
+		top    ns4.Obj
+		threat int
+	)
+	for _, e := range t.list {
+		if top == nil || e.threat > threat {
+			top, threat = e.unit, e.threat
+		}
+	}
+	return top, threat
+}
+
+// threatUpdate accumulates threat from the damage dealt to the shared pool, handles taunts and steers the guard.
+func (g *Guard) threatUpdate(delta int) {
+	t := &g.threat
+	// Forget players that died or left the room.
+	for _, e := range append([]threatEntry(nil), t.list...) {
+		if !g.s.InRoom(e.unit) || e.unit.CurrentHealth() <= 0 {
+			t.Remove(e.unit)
+		}
+	}
+	// Damage done to this guard generates threat for the attackers.
+	if delta < 0 {
+		g.addDamageThreat(-delta)
+	}
+	g.checkTaunt()
+	// Switch the target only periodically, to avoid jittering between players with similar threat.
+	if g.frame%ThreatUpdateInterval != 0 {
+		return
+	}
+	targ := g.threatTarget()
+	if targ == nil || targ == t.target {
+		return
+	}
+	t.target = targ
+	if !ThreatAttack {
+		// Only track the threat, the built-in AI selects the target.
+		return
+	}
+	g.unit.Attack(targ)
+}
+
+// addDamageThreat adds threat for the damage dealt to the guard.
+// Damage is split between all players attacking the guard. If none of them are known, the closest player gets it.
+func (g *Guard) addDamageThreat(dmg int) {
+	var attackers []ns4.Obj
+	g.s.EachPlayerInRoom(func(u ns4.Obj) {
+		if g.unit.IsAttackedBy(u) {
+			attackers = append(attackers, u)
+		}
+	})
+	if len(attackers) == 0 {
+		if u := g.closestPlayer(); u != nil {
+			attackers = append(attackers, u)
+		}
+	}
+	if len(attackers) == 0 {
+		return
+	}
+	threat := dmg * ThreatPerDamage / len(attackers)
+	if threat <= 0 {
+		threat = 1
+	}
+	for _, u := range attackers {
+		g.threat.Add(u, threat)
+	}
+}
+
+// checkTaunt checks if a player taunted the guard with TauntSpell.
+// If the caster cannot be found, the taunt is removed without effect.
+func (g *Guard) checkTaunt() {
+	if !g.unit.HasEnchant(TauntEnchant) {
+		return
+	}
+	g.unit.EnchantOff(TauntEnchant)
+	u := g.tauntCaster()
+	if u == nil {
+		return
+	}
+	// a single cast can only taunt one guard
+	delete(g.s.manaSpentAt, u)
+	t := &g.threat
+	// Taunter gets the top threat with some margin, so the guard will stay on it even after the taunt ends.
+	_, top := t.Top()
+	if cur := t.Get(u); cur < top+ThreatTauntBonus {
+		t.Set(u, top+ThreatTauntBonus)
+	}
+	t.tauntBy = u
+	t.tauntEnd = g.frame + ThreatTauntDur*ns4.FrameRate()
+	// Force the target switch on the next threat update.
+	t.target = nil
+}
+
+// threatTarget selects a player the guard should attack.
+func (g *Guard) threatTarget() ns4.Obj {
+	t := &g.threat
+	if t.tauntBy != nil && g.frame < t.tauntEnd {
+		return t.tauntBy
+	}
+	top, threat := t.Top()
+	if top == nil {
+		return nil
+	}
+	if t.target == nil || t.target == top {
+		return top
+	}
+	// Other players must exceed the threat of the current target by a margin to pull the guard away.
+	if cur := t.Get(t.target); threat*100 < cur*ThreatSwitchPerc {
+		return t.target
+	}
+	return top
+}
+
+// closestPlayer finds the closest alive player in the room.
+func (g *Guard) closestPlayer() ns4.Obj {
+	var (
+		closest ns4.Obj
+		dist    float64
+	)
+	g.s.EachPlayerInRoom(func(u ns4.Obj) {
+		if d := g.unit.Pos().Sub(u.Pos()).Len(); closest == nil || d < dist {
+			closest, dist = u, d
+		}
+	})
+	return closest
+}
+
+// manaUpdate records mana of players in the room. If detect is set, it also remembers players that spent
+// enough mana since the last record to cast TauntSpell.
+// Scripts cannot see who applied an enchant, so this is used to find the taunting player.
+func (s *State) manaUpdate(detect bool) {
+	if s.manaPrev == nil {
+		s.manaPrev = make(map[ns4.Obj]int)
+		s.manaSpentAt = make(map[ns4.Obj]int)
+	}
+	s.EachPlayerInRoom(func(u ns4.Obj) {
+		mana := u.CurrentMana()
+		if prev, ok := s.manaPrev[u]; ok && detect && prev-mana >= TauntManaCost {
+			s.manaSpentAt[u] = s.frame
+		}
+		s.manaPrev[u] = mana
+	})
+}
+
+// tauntCaster returns the player that cast TauntSpell on the guard during the last TauntCastWindow frames, or nil.
+// Scripts cannot see who applied an enchant, so the caster must have spent the mana and must be attacking the guard.
+// If several players match, the caster is unknown and nil is returned, so the taunt is never credited by a guess.
+func (g *Guard) tauntCaster() ns4.Obj {
+	var caster ns4.Obj
+	for u, f := range g.s.manaSpentAt {
+		if g.s.frame-f > TauntCastWindow || !g.s.InRoom(u) || u.CurrentHealth() <= 0 {
+			continue
+		}
+		if !g.unit.IsAttackedBy(u) {
+			continue
+		}
+		if caster != nil {
+			return nil
+		}
+		caster = u
+	}
+	return caster
+}
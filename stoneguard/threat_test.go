@@ -0,0 +1,164 @@
+package stoneguard
+
+import (
+	"testing"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+// testObj is a fake object for tests that only need distinct object handles.
+type testObj struct {
+	ns4.Obj
+	name string
+}
+
+func (o *testObj) String() string {
+	return o.name
+}
+
+func newTestObjs(n int) []ns4.Obj {
+	out := make([]ns4.Obj, n)
+	for i := range out {
+		out[i] = &testObj{name: string(rune('A' + i))}
+	}
+	return out
+}
+
+func TestThreatTop(t *testing.T) {
+	u := newTestObjs(3)
+	cases := []struct {
+		name   string
+		fill   func(t *threatTable)
+		top    ns4.Obj
+		threat int
+	}{
+		{
+			name: "empty",
+			fill: func(t *threatTable) {},
+		},
+		{
+			name: "single",
+			fill: func(t *threatTable) {
+				t.Add(u[0], 5)
+			},
+			top: u[0], threat: 5,
+		},
+		{
+			name: "accumulated",
+			fill: func(t *threatTable) {
+				t.Add(u[0], 10)
+				t.Add(u[1], 6)
+				t.Add(u[1], 6)
+			},
+			top: u[1], threat: 12,
+		},
+		{
+			name: "tie keeps first",
+			fill: func(t *threatTable) {
+				t.Add(u[0], 10)
+				t.Add(u[1], 10)
+			},
+			top: u[0], threat: 10,
+		},
+		{
+			name: "set overrides",
+			fill: func(t *threatTable) {
+				t.Add(u[0], 50)
+				t.Add(u[1], 10)
+				t.Set(u[0], 1)
+			},
+			top: u[1], threat: 10,
+		},
+		{
+			name: "removed",
+			fill: func(t *threatTable) {
+				t.Add(u[0], 50)
+				t.Add(u[1], 10)
+				t.Add(u[2], 20)
+				t.Remove(u[0])
+			},
+			top: u[2], threat: 20,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var tbl threatTable
+			c.fill(&tbl)
+			top, threat := tbl.Top()
+			if top != c.top || threat != c.threat {
+				t.Fatalf("unexpected top: %v (%d), expected %v (%d)", top, threat, c.top, c.threat)
+			}
+		})
+	}
+}
+
+func TestThreatRemove(t *testing.T) {
+	u := newTestObjs(2)
+	var tbl threatTable
+	tbl.Add(u[0], 10)
+	tbl.Add(u[1], 20)
+	tbl.target = u[1]
+	tbl.tauntBy = u[1]
+	tbl.Remove(u[1])
+	if tbl.Get(u[1]) != 0 || tbl.target != nil || tbl.tauntBy != nil {
+		t.Fatalf("player was not removed: threat=%d, target=%v, taunt=%v", tbl.Get(u[1]), tbl.target, tbl.tauntBy)
+	}
+	if tbl.Get(u[0]) != 10 {
+		t.Fatalf("unexpected threat of other player: %d", tbl.Get(u[0]))
+	}
+}
+
+func TestThreatTarget(t *testing.T) {
+	u := newTestObjs(3)
+	cases := []struct {
+		name    string
+		threat  []int // threat of each player, zero is not added
+		target  int   // current target, or -1
+		tauntBy int   // taunting player, or -1
+		exp     int   // expected target, or -1
+	}{
+		{name: "no threat", threat: []int{0, 0, 0}, target: -1, tauntBy: -1, exp: -1},
+		{name: "no target", threat: []int{10, 20, 0}, target: -1, tauntBy: -1, exp: 1},
+		{name: "target is top", threat: []int{10, 20, 0}, target: 1, tauntBy: -1, exp: 1},
+		{name: "below margin", threat: []int{100, 109, 0}, target: 0, tauntBy: -1, exp: 0},
+		{name: "at margin", threat: []int{100, 110, 0}, target: 0, tauntBy: -1, exp: 1},
+		{name: "above margin", threat: []int{100, 50, 200}, target: 0, tauntBy: -1, exp: 2},
+		{name: "taunt", threat: []int{100, 500, 10}, target: 1, tauntBy: 2, exp: 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := &Guard{frame: 10}
+			for i, v := range c.threat {
+				if v != 0 {
+					g.threat.Add(u[i], v)
+				}
+			}
+			if c.target >= 0 {
+				g.threat.target = u[c.target]
+			}
+			if c.tauntBy >= 0 {
+				g.threat.tauntBy = u[c.tauntBy]
+				g.threat.tauntEnd = g.frame + 1
+			}
+			var exp ns4.Obj
+			if c.exp >= 0 {
+				exp = u[c.exp]
+			}
+			if got := g.threatTarget(); got != exp {
+				t.Fatalf("unexpected target: %v, expected %v", got, exp)
+			}
+		})
+	}
+}
+
+func TestThreatTauntEnds(t *testing.T) {
+	u := newTestObjs(2)
+	g := &Guard{frame: 10}
+	g.threat.Add(u[0], 100)
+	g.threat.Add(u[1], 10)
+	g.threat.tauntBy = u[1]
+	g.threat.tauntEnd = 10
+	if got := g.threatTarget(); got != u[0] {
+		t.Fatalf("taunt did not end: %v", got)
+	}
+}
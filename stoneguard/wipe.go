@@ -0,0 +1,78 @@
+package stoneguard
+
+// This file contains wipe recovery logic. It moves all participants out of the room when the raid wipes,
+// so the fight can be pulled again cleanly.
+
+import (
+	"fmt"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+	"github.com/noxworld-dev/noxscript/ns/v4/enchant"
+)
+
+// wipeEnchants is a list of enchants applied by the encounter that must be cleared after a wipe.
+var wipeEnchants = []enchant.Enchant{
+	enchant.CONFUSED,
+	enchant.HELD,
+}
+
+// wipe handles the raid wipe: moves participants to the graveyard and resets the encounter.
+func (s *State) wipe() {
+	if Debug {
+		fmt.Println("Raid wiped!")
+	}
+	notify(EventWipe, 1)
+	var pending []ns4.Player
+	// Only participants are moved. Players that were held outside are not affected.
+	for _, e := range s.roster {
+		if e.status == RosterDisconnected {
+			continue
+		}
+		pl := e.pl
+		u := pl.Unit()
+		if u == nil {
+			continue
+		}
+		if u.CurrentHealth() <= 0 {
+			// Dead players will be moved once they respawn.
+			if WipeGraveyardRespawn {
+				pending = append(pending, pl)
+			}
+			continue
+		}
+		s.moveToGraveyard(u)
+	}
+	// creatures must not keep fighting the guards after the reset
+	s.dispelCreatures()
+	s.Reset()
+	// Reset clears the pending list, so fill it after.
+	s.wipePending = pending
+}
+
+// moveToGraveyard moves a single participant to the graveyard and clears encounter enchants.
+func (s *State) moveToGraveyard(u ns4.Obj) {
+	for _, e := range wipeEnchants {
+		u.EnchantOff(e)
+	}
+	if WipeRestore {
+		u.SetHealth(u.MaxHealth())
+		u.SetMana(u.MaxMana())
+	}
+	u.SetPos(graveyardPos)
+}
+
+// wipePendingUpdate moves dead participants to the graveyard after they respawn.
+func (s *State) wipePendingUpdate() {
+	for i := 0; i < len(s.wipePending); i++ {
+		pl := s.wipePending[i]
+		u := pl.Unit()
+		if u != nil && u.CurrentHealth() <= 0 {
+			continue // still dead, check again on the next frame
+		}
+		if u != nil {
+			s.moveToGraveyard(u)
+		}
+		s.wipePending = append(s.wipePending[:i], s.wipePending[i+1:]...)
+		i--
+	}
+}
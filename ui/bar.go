@@ -0,0 +1,105 @@
+package ui
+
+import (
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+// Orientation of the progress bar.
+type Orientation int
+
+const (
+	// Horizontal bar fills from left to right.
+	Horizontal = Orientation(iota)
+	// Vertical bar fills from bottom to top.
+	Vertical
+)
+
+// BarStyle configures the look of the progress bar.
+type BarStyle struct {
+	Orientation Orientation
+	Length      float32    // length of the bar
+	Slots       int        // number of objects in the bar
+	Offset      ns4.Pointf // offset of the bar center from the anchor
+	Models      []string   // models for filled slots, one for each fill level (from low to full)
+	EmptyModel  string     // model for empty slots; empty slots are hidden if not set
+	MinSlots    int        // number of slots shown as filled even if the value is zero
+}
+
+// NewProgressBar creates a progress bar attached to a unit or a fixed position.
+func NewProgressBar(anchor ns4.Positioner, style BarStyle) *ProgressBar {
+	bar := &ProgressBar{anchor: anchor, style: style, slots: newSlotSet(style.Slots)}
+	bar.Update()
+	return bar
+}
+
+// ProgressBar is a bar made of objects that shows a value in [0, 1] range.
+type ProgressBar struct {
+	anchor ns4.Positioner
+	style  BarStyle
+	val    float32
+	hidden bool
+	slots  slotSet
+}
+
+// Set the value of the bar. It will be displayed on the next Update.
+func (bar *ProgressBar) Set(val float32) {
+	bar.val = val
+}
+
+// Value returns current value of the bar.
+func (bar *ProgressBar) Value() float32 {
+	return bar.val
+}
+
+// Show the bar.
+func (bar *ProgressBar) Show() {
+	bar.hidden = false
+}
+
+// Hide the bar.
+func (bar *ProgressBar) Hide() {
+	bar.hidden = true
+}
+
+// Delete the bar objects.
+func (bar *ProgressBar) Delete() {
+	bar.slots.Delete()
+}
+
+// Objects returns the bar objects.
+func (bar *ProgressBar) Objects() ns4.Objects {
+	return bar.slots.Objects()
+}
+
+// slotPos returns position for a given slot.
+func (bar *ProgressBar) slotPos(center ns4.Pointf, i int) ns4.Pointf {
+	l := bar.style.Length
+	perc := float32(i) / float32(bar.style.Slots)
+	switch bar.style.Orientation {
+	case Vertical:
+		return ns4.Pointf{X: center.X, Y: center.Y + l/2 - perc*l}
+	default:
+		return ns4.Pointf{X: center.X - l/2 + perc*l, Y: center.Y}
+	}
+}
+
+// Update the bar position and fill.
+func (bar *ProgressBar) Update() {
+	center := bar.anchor.Pos().Add(bar.style.Offset)
+	val := clamp(bar.val)
+	filled := int(val * float32(bar.style.Slots))
+	if filled < bar.style.MinSlots {
+		filled = bar.style.MinSlots
+	}
+	model := fillModel(bar.style.Models, val)
+	for i := 0; i < bar.style.Slots; i++ {
+		pos := bar.slotPos(center, i)
+		if i < filled {
+			bar.slots.Set(i, model, pos, !bar.hidden)
+		} else if bar.style.EmptyModel != "" {
+			bar.slots.Set(i, bar.style.EmptyModel, pos, !bar.hidden)
+		} else {
+			bar.slots.Set(i, bar.slots.models[i], pos, false)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"testing"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+func TestClamp(t *testing.T) {
+	cases := []struct {
+		val, exp float32
+	}{
+		{-1, 0},
+		{0, 0},
+		{0.5, 0.5},
+		{1, 1},
+		{1.5, 1},
+	}
+	for _, c := range cases {
+		if got := clamp(c.val); got != c.exp {
+			t.Errorf("clamp(%v) = %v, expected %v", c.val, got, c.exp)
+		}
+	}
+}
+
+func TestFillModel(t *testing.T) {
+	models := []string{"low", "mid", "high"}
+	cases := []struct {
+		models []string
+		val    float32
+		exp    string
+	}{
+		{nil, 0.5, ""},
+		{models[:1], 0, "low"},
+		{models[:1], 1, "low"},
+		{models, -1, "low"},
+		{models, 0, "low"},
+		{models, 0.3, "low"},
+		{models, 0.34, "mid"},
+		{models, 0.66, "mid"},
+		{models, 0.67, "high"},
+		{models, 1, "high"},
+		{models, 2, "high"},
+	}
+	for _, c := range cases {
+		if got := fillModel(c.models, c.val); got != c.exp {
+			t.Errorf("fillModel(%v, %v) = %q, expected %q", c.models, c.val, got, c.exp)
+		}
+	}
+}
+
+func TestSlotPos(t *testing.T) {
+	center := ns4.Ptf(100, 100)
+	cases := []struct {
+		name  string
+		style BarStyle
+		slot  int
+		exp   ns4.Pointf
+	}{
+		{"horizontal first", BarStyle{Orientation: Horizontal, Length: 40, Slots: 4}, 0, ns4.Ptf(80, 100)},
+		{"horizontal last", BarStyle{Orientation: Horizontal, Length: 40, Slots: 4}, 3, ns4.Ptf(110, 100)},
+		{"vertical first", BarStyle{Orientation: Vertical, Length: 40, Slots: 4}, 0, ns4.Ptf(100, 120)},
+		{"vertical last", BarStyle{Orientation: Vertical, Length: 40, Slots: 4}, 3, ns4.Ptf(100, 90)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			bar := &ProgressBar{style: c.style}
+			if got := bar.slotPos(center, c.slot); got != c.exp {
+				t.Fatalf("unexpected position: %v, expected %v", got, c.exp)
+			}
+		})
+	}
+}
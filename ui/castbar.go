@@ -0,0 +1,73 @@
+package ui
+
+import (
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+// NewCastBar creates a hidden cast bar attached to a unit or a fixed position.
+func NewCastBar(anchor ns4.Positioner, style BarStyle) *CastBar {
+	cb := &CastBar{bar: NewProgressBar(anchor, style)}
+	if obj, ok := anchor.(ns4.Obj); ok {
+		cb.label = obj
+	}
+	cb.bar.Hide()
+	cb.bar.Update()
+	return cb
+}
+
+// CastBar is a progress bar that fills while a spell is being cast. Cast name is shown above the anchor unit.
+type CastBar struct {
+	bar   *ProgressBar
+	label ns4.Obj
+	name  string
+	frame int
+	dur   int
+}
+
+// Start the cast with a given name and duration in frames.
+func (cb *CastBar) Start(name string, dur int) {
+	cb.name = name
+	cb.frame = 0
+	cb.dur = dur
+	cb.bar.Set(0)
+	cb.bar.Show()
+	if cb.label != nil && name != "" {
+		cb.label.ChatStrTimer(name, ns4.Frames(dur))
+	}
+}
+
+// Stop the cast and hide the bar.
+func (cb *CastBar) Stop() {
+	if cb.dur != 0 && cb.label != nil && cb.name != "" {
+		cb.label.DestroyChat()
+	}
+	cb.dur = 0
+	cb.bar.Hide()
+}
+
+// Active checks if the cast is in progress.
+func (cb *CastBar) Active() bool {
+	return cb.dur != 0
+}
+
+// Delete the cast bar objects.
+func (cb *CastBar) Delete() {
+	cb.bar.Delete()
+}
+
+// Objects returns the cast bar objects.
+func (cb *CastBar) Objects() ns4.Objects {
+	return cb.bar.Objects()
+}
+
+// Update the cast progress. The cast stops automatically when completed.
+func (cb *CastBar) Update() {
+	if cb.dur != 0 {
+		cb.frame++
+		cb.bar.Set(float32(cb.frame) / float32(cb.dur))
+		if cb.frame >= cb.dur {
+			cb.Stop()
+		}
+	}
+	cb.bar.Update()
+}
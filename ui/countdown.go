@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"strconv"
+
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+// NewCountdown creates a countdown timer for a given number of frames, attached to a unit or a fixed position.
+// If attached to a unit, the number of seconds left is shown above it.
+func NewCountdown(anchor ns4.Positioner, style BarStyle, dur int) *Countdown {
+	c := &Countdown{bar: NewProgressBar(anchor, style), left: dur, dur: dur}
+	if obj, ok := anchor.(ns4.Obj); ok {
+		c.label = obj
+	}
+	c.bar.Set(1)
+	c.bar.Update()
+	return c
+}
+
+// Countdown is a progress bar that empties over time.
+type Countdown struct {
+	bar   *ProgressBar
+	label ns4.Obj
+	left  int
+	dur   int
+}
+
+// Done checks if the countdown reached zero.
+func (c *Countdown) Done() bool {
+	return c.left <= 0
+}
+
+// Left returns the number of frames left.
+func (c *Countdown) Left() int {
+	return c.left
+}
+
+// Show the countdown.
+func (c *Countdown) Show() {
+	c.bar.Show()
+}
+
+// Hide the countdown.
+func (c *Countdown) Hide() {
+	c.bar.Hide()
+}
+
+// Delete the countdown objects.
+func (c *Countdown) Delete() {
+	c.bar.Delete()
+}
+
+// Objects returns the countdown objects.
+func (c *Countdown) Objects() ns4.Objects {
+	return c.bar.Objects()
+}
+
+// Update the countdown.
+func (c *Countdown) Update() {
+	if c.left > 0 {
+		if c.label != nil && c.left%ns4.FrameRate() == 0 {
+			c.label.ChatStrTimer(strconv.Itoa(c.left/ns4.FrameRate()), ns4.Seconds(1))
+		}
+		c.left--
+		c.bar.Set(float32(c.left) / float32(c.dur))
+	}
+	c.bar.Update()
+}
@@ -24,10 +24,21 @@ type HealthBar struct {
 }
 
 func (hp *HealthBar) Delete() {
-	hp.left.Delete()
-	hp.mover.Delete()
-	hp.cur.Delete()
-	hp.right.Delete()
+	for _, o := range hp.Objects() {
+		o.Delete()
+	}
+	hp.left, hp.mover, hp.cur, hp.right = nil, nil, nil, nil
+}
+
+// Objects returns the health bar objects, unless they were deleted.
+func (hp *HealthBar) Objects() ns4.Objects {
+	var out ns4.Objects
+	for _, o := range []ns4.Obj{hp.left, hp.mover, hp.cur, hp.right} {
+		if o != nil {
+			out = append(out, o)
+		}
+	}
+	return out
 }
 
 func (hp *HealthBar) Update() {
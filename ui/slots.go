@@ -0,0 +1,89 @@
+package ui
+
+import (
+	ns4 "github.com/noxworld-dev/noxscript/ns/v4"
+)
+
+// slotSet is a set of objects used as slots of a widget.
+// Each slot can change its model, in which case the object is recreated.
+type slotSet struct {
+	objs   []ns4.Obj
+	models []string
+}
+
+func newSlotSet(n int) slotSet {
+	return slotSet{
+		objs:   make([]ns4.Obj, n),
+		models: make([]string, n),
+	}
+}
+
+// Set updates the slot model, position and visibility.
+func (s *slotSet) Set(i int, model string, pos ns4.Pointf, show bool) {
+	if s.models[i] != model {
+		if s.objs[i] != nil {
+			s.objs[i].Delete()
+			s.objs[i] = nil
+		}
+		s.models[i] = model
+	}
+	if model == "" {
+		return
+	}
+	if s.objs[i] == nil {
+		s.objs[i] = ns4.CreateObject(model, pos)
+	}
+	s.objs[i].SetPos(pos)
+	if s.objs[i].IsEnabled() != show {
+		s.objs[i].Enable(show)
+	}
+}
+
+// Objects returns all slot objects that currently exist.
+func (s *slotSet) Objects() ns4.Objects {
+	var out ns4.Objects
+	for _, o := range s.objs {
+		if o != nil {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// Delete all slot objects.
+func (s *slotSet) Delete() {
+	for i, o := range s.objs {
+		if o != nil {
+			o.Delete()
+		}
+		s.objs[i] = nil
+		s.models[i] = ""
+	}
+}
+
+// fillModel selects a model for a given fill level from the list.
+// The first model is used for the lowest fill level, the last one - for the full widget.
+func fillModel(models []string, val float32) string {
+	if len(models) == 0 {
+		return ""
+	}
+	i := int(val * float32(len(models)))
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(models) {
+		i = len(models) - 1
+	}
+	return models[i]
+}
+
+// clamp limits the value to [0, 1] range.
+func clamp(val float32) float32 {
+	if val < 0 {
+		return 0
+	}
+	if val > 1 {
+		return 1
+	}
+	return val
+}